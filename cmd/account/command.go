@@ -1,13 +1,11 @@
 package account
 
 import (
-	"fmt"
-	"log/slog"
-	"strconv"
-
 	"github.com/fbufler/comdirect/config"
-	"github.com/fbufler/comdirect/internal/convert"
+	"github.com/fbufler/comdirect/internal/completion"
 	"github.com/fbufler/comdirect/internal/flows"
+	"github.com/fbufler/comdirect/internal/listing"
+	"github.com/fbufler/comdirect/internal/output"
 	"github.com/fbufler/comdirect/pkg/comdirect"
 	"github.com/spf13/cobra"
 )
@@ -16,8 +14,9 @@ func Command() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "account",
 		Short: "Retrieve Bank Account Information",
+		Long:  "Retrieve Bank Account Information\n\nAccounts are selected by account ID, IBAN, accountDisplayId, a configured alias or a unique prefix of one of them.",
 	}
-	cmd.PersistentFlags().StringP("output", "o", "json", "Output format (json, yaml)")
+	output.AddFlags(cmd)
 	cmd.AddCommand(balancesCmd)
 	cmd.AddCommand(balanceCmd)
 	cmd.AddCommand(transactionsCmd)
@@ -27,101 +26,91 @@ func Command() *cobra.Command {
 var balancesCmd = &cobra.Command{
 	Use:   "balances",
 	Short: "Retrieve Account Balances",
-	Run:   balances,
+	RunE:  balances,
 }
 
-func balances(cmd *cobra.Command, args []string) {
-	cfg := config.Get()
-	excludeAccount := cmd.Flag("exclude-account").Changed
-	data, err := flows.AccountBalances(cfg, excludeAccount)
+func balances(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+	list, err := listing.OptionsFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	client, token, err := flows.Bootstrap(cfg)
 	if err != nil {
-		cmd.PrintErrln(err)
-		return
+		return err
 	}
-	handleOutput(cmd, data)
+	excludeAccount := cmd.Flag("exclude-account").Changed
+	return output.PrintPages(cmd, flows.IndexAccounts(cfg, flows.AccountBalances(client, token, excludeAccount, list)))
 }
 
 var balanceCmd = &cobra.Command{
-	Use:   "balance <account-id>",
-	Short: "Retrieve Account Balance",
-	Args:  cobra.ExactArgs(1),
-	Run:   balance,
+	Use:               "balance <account>",
+	Short:             "Retrieve Account Balance",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completion.Accounts,
+	RunE:              balance,
 }
 
-func balance(cmd *cobra.Command, args []string) {
-	cfg := config.Get()
-	accountID := args[0]
-	data, err := flows.AccountBalance(cfg, accountID)
+func balance(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+	client, token, err := flows.Bootstrap(cfg)
+	if err != nil {
+		return err
+	}
+	accountID, err := flows.ResolveAccountID(cfg, client, token, args[0])
 	if err != nil {
-		cmd.PrintErrln(err)
-		return
+		return err
 	}
-	handleOutput(cmd, data)
+	data, err := flows.AccountBalance(client, token, accountID)
+	if err != nil {
+		return err
+	}
+	return output.Print(cmd, data)
 }
 
 var transactionsCmd = &cobra.Command{
-	Use:   "transactions <account-id>",
-	Short: "Retrieve Account Transactions",
-	Args:  cobra.ExactArgs(1),
-	Run:   transactions,
+	Use:               "transactions <account>",
+	Short:             "Retrieve Account Transactions",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completion.Accounts,
+	RunE:              transactions,
 }
 
-func transactions(cmd *cobra.Command, args []string) {
-	cfg := config.Get()
-	accountID := args[0]
+func transactions(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
 	transactionState := comdirect.TransactionState(cmd.Flag("state").Value.String())
 	includeAccount := cmd.Flag("include-account").Changed
-	countInput := cmd.Flag("count").Value.String()
-
-	var data string
-	var err error
-	if countInput != "" {
-		count, err := strconv.Atoi(countInput)
-		if err != nil {
-			cmd.PrintErrln(err)
-			return
-		}
-		data, err = flows.PaginatedAccountTransactions(cfg, accountID, count, includeAccount)
-		if err != nil {
-			cmd.PrintErrln(err)
-			return
-		}
-	} else {
-		data, err = flows.AccountTransactions(cfg, accountID, transactionState, includeAccount)
-		if err != nil {
-			cmd.PrintErrln(err)
-			return
-		}
+	list, err := listing.OptionsFromFlags(cmd)
+	if err != nil {
+		return err
 	}
-	handleOutput(cmd, data)
-}
 
-func handleOutput(cmd *cobra.Command, data string) {
-	output := cmd.Flag("output").Value.String()
-	slog.Info(fmt.Sprintf("Output format: %s", output))
-	switch output {
-	case "json":
-		json, err := convert.JSONToReadableJSON(data)
-		if err != nil {
-			cmd.PrintErrln(err)
-			return
-		}
-		cmd.Println(json)
-	case "yaml":
-		yaml, err := convert.JSONToYAML(data)
-		if err != nil {
-			cmd.PrintErrln(err)
-			return
-		}
-		cmd.Println(yaml)
-	default:
-		cmd.PrintErrln("Unsupported output format")
+	client, token, err := flows.Bootstrap(cfg)
+	if err != nil {
+		return err
+	}
+	accountID, err := flows.ResolveAccountID(cfg, client, token, args[0])
+	if err != nil {
+		return err
 	}
+	return output.PrintPages(cmd, flows.AccountTransactions(client, token, accountID, transactionState, includeAccount, list))
 }
 
 func init() {
 	balancesCmd.Flags().BoolP("exclude-account", "e", false, "Exclude Account")
-	transactionsCmd.Flags().StringP("state", "s", string(comdirect.TransactionStateBoth), "Transaction State (BOTH, BOOKED, NOTBOOKED)")
+	transactionsCmd.Flags().StringP("state", "s", string(comdirect.TransactionStateBoth), "Transaction State (BOTH, BOOKED, NOTBOOKED), always BOOKED with --count, --all, --since or --until")
 	transactionsCmd.Flags().BoolP("include-account", "i", false, "Include Account")
-	transactionsCmd.Flags().StringP("count", "c", "", "Amount of Transactions, by default 20")
+	completion.Flag(transactionsCmd, "state", completion.Values(string(comdirect.TransactionStateBoth), string(comdirect.TransactionStateBooked), string(comdirect.TransactionStateNotBooked)))
+	listing.AddFlags(balancesCmd)
+	listing.AddFlags(transactionsCmd)
+	listing.AddDateFlags(transactionsCmd)
 }
@@ -0,0 +1,167 @@
+package configcmd
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fbufler/comdirect/config"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+func Command() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "config",
+		Short: "Manage the Configuration",
+	}
+
+	cmd.AddCommand(validateCmd)
+	cmd.AddCommand(showCmd)
+	cmd.AddCommand(initCmd)
+
+	return cmd
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the Configuration and all Profiles",
+	RunE:  validate,
+}
+
+func validate(cmd *cobra.Command, args []string) error {
+	// config.Get stops at the first invalid setting of the active profile, Validate reports all of them
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	source := config.File()
+	if source == "" {
+		source = "The configuration from the environment"
+	}
+	cmd.Printf("%s is valid\n", source)
+	return nil
+}
+
+var showCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the Configuration with masked Secrets",
+	Long: `Show the values set in the configuration file with masked secrets.
+With --effective all values are shown as they are used, after applying defaults, environment variables, flags and the selected profile.`,
+	RunE: show,
+}
+
+func show(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	effective := cmd.Flag("effective").Changed
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+	for _, setting := range cfg.Settings() {
+		if !effective && !strings.HasPrefix(setting.Source, config.SourceFile) {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", setting.Key, setting.Value, setting.Source)
+	}
+	return w.Flush()
+}
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create a Configuration File interactively",
+	RunE:  initConfig,
+}
+
+func initConfig(cmd *cobra.Command, args []string) error {
+	path := cmd.Flag("file").Value.String()
+	overwrite := cmd.Flag("force").Changed
+	if _, err := os.Stat(path); err == nil && !overwrite {
+		return fmt.Errorf("%s already exists, use --force to overwrite it", path)
+	}
+
+	p := &prompter{in: bufio.NewReader(cmd.InOrStdin()), out: cmd.ErrOrStderr()}
+	cfg := &config.Config{}
+	cfg.Client.ClientID = p.ask("Client ID")
+	cfg.Client.ClientSecret = p.askSecret("Client secret (leave empty to configure a file or command later)")
+	cfg.Client.Zugangsnummer = p.ask("Zugangsnummer")
+	cfg.Client.Pin = p.askSecret("PIN (leave empty to be asked on login)")
+	cfg.Cli.EnableCache = p.confirm("Cache the session between runs?")
+	if cfg.Cli.EnableCache {
+		cfg.Cli.EncryptionKey = p.askSecret("Cache encryption key, at least 16 characters (leave empty to generate one)")
+		if cfg.Cli.EncryptionKey == "" {
+			cfg.Cli.EncryptionKey = generateEncryptionKey()
+		}
+	}
+	if p.err != nil {
+		return p.err
+	}
+
+	if err := config.WriteFile(path, cfg, overwrite); err != nil {
+		return err
+	}
+	cmd.Printf("Configuration written to %s\n", path)
+	return nil
+}
+
+// prompter asks questions until the first error, which is kept in err.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+	err error
+}
+
+func (p *prompter) ask(question string) string {
+	if p.err != nil {
+		return ""
+	}
+	fmt.Fprintf(p.out, "%s: ", question)
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		p.err = err
+	}
+	return strings.TrimSpace(line)
+}
+
+// askSecret reads the answer without echo if stdin is a terminal.
+func (p *prompter) askSecret(question string) string {
+	fd := int(os.Stdin.Fd())
+	if p.err != nil || !term.IsTerminal(fd) {
+		return p.ask(question)
+	}
+	fmt.Fprintf(p.out, "%s: ", question)
+	secret, err := term.ReadPassword(fd)
+	fmt.Fprintln(p.out)
+	if err != nil {
+		p.err = err
+	}
+	return strings.TrimSpace(string(secret))
+}
+
+func (p *prompter) confirm(question string) bool {
+	answer := strings.ToLower(p.ask(question + " [y/N]"))
+	return answer == "y" || answer == "yes"
+}
+
+func generateEncryptionKey() string {
+	key := make([]byte, 24)
+	rand.Read(key)
+	return base64.RawURLEncoding.EncodeToString(key)
+}
+
+func init() {
+	showCmd.Flags().Bool("effective", false, "Show all values as they are used, including defaults and environment variables")
+
+	initCmd.Flags().String("file", config.DefaultFile(), "Path of the configuration file")
+	initCmd.Flags().Bool("force", false, "Overwrite an existing configuration file")
+}
@@ -0,0 +1,45 @@
+package configcmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestValidateReportsAllProfiles(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	content := `profile: work
+client:
+  client-id: id
+  client-secret: secret
+  zugangsnummer: "12345678"
+profiles:
+  work:
+    api-url: "not a url"
+  family:
+    zugangsnummer: ""
+    client-id: ""
+    token-url: "ftp://example.com"
+`
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	viper.SetConfigFile(file)
+
+	cmd := Command()
+	cmd.SetArgs([]string{"validate"})
+	cmd.SetOut(&strings.Builder{})
+	cmd.SetErr(&strings.Builder{})
+	err := cmd.Execute()
+	if err == nil {
+		t.Fatal("validate accepted two broken profiles")
+	}
+	for _, want := range []string{"profiles.work.api-url", "profiles.family.token-url"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not report %s:\n%s", want, err)
+		}
+	}
+}
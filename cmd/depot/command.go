@@ -1,13 +1,14 @@
 package depot
 
 import (
-	"fmt"
-	"log/slog"
 	"time"
 
 	"github.com/fbufler/comdirect/config"
+	"github.com/fbufler/comdirect/internal/completion"
 	"github.com/fbufler/comdirect/internal/convert"
 	"github.com/fbufler/comdirect/internal/flows"
+	"github.com/fbufler/comdirect/internal/listing"
+	"github.com/fbufler/comdirect/internal/output"
 	"github.com/fbufler/comdirect/pkg/comdirect"
 	"github.com/spf13/cobra"
 )
@@ -16,9 +17,10 @@ func Command() *cobra.Command {
 	var cmd = &cobra.Command{
 		Use:   "depot",
 		Short: "Retrieve Depot Information",
+		Long:  "Retrieve Depot Information\n\nDepots are selected by depot ID, depotDisplayId, a configured alias or a unique prefix of one of them.",
 	}
 
-	cmd.PersistentFlags().StringP("output", "o", "json", "Output format (json, yaml)")
+	output.AddFlags(cmd)
 	cmd.AddCommand(depotsCmd)
 	cmd.AddCommand(depotPositionCmd)
 	cmd.AddCommand(depotPositionsCmd)
@@ -30,123 +32,138 @@ func Command() *cobra.Command {
 var depotsCmd = &cobra.Command{
 	Use:   "depots",
 	Short: "Retrieve Depots",
-	Run:   depots,
+	RunE:  depots,
 }
 
-func depots(cmd *cobra.Command, args []string) {
-	cfg := config.Get()
-	data, err := flows.Depots(cfg)
+func depots(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
 	if err != nil {
-		cmd.PrintErrln(err)
-		return
+		return err
 	}
-	handleOutput(cmd, data)
+	list, err := listing.OptionsFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	client, token, err := flows.Bootstrap(cfg)
+	if err != nil {
+		return err
+	}
+	return output.PrintPages(cmd, flows.IndexDepots(cfg, flows.Depots(client, token, list)))
 }
 
 var depotPositionCmd = &cobra.Command{
-	Use:   "position <depot-id> <position-id>",
-	Short: "Retrieve Depot Position",
-	Args:  cobra.ExactArgs(2),
-	Run:   depotPosition,
+	Use:               "position <depot> <position-id>",
+	Short:             "Retrieve Depot Position",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completion.DepotPositions,
+	RunE:              depotPosition,
 }
 
-func depotPosition(cmd *cobra.Command, args []string) {
-	cfg := config.Get()
-	depotID := args[0]
+func depotPosition(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+	client, token, err := flows.Bootstrap(cfg)
+	if err != nil {
+		return err
+	}
+	depotID, err := flows.ResolveDepotID(cfg, client, token, args[0])
+	if err != nil {
+		return err
+	}
 	positionID := args[1]
 	includeInstrument := cmd.Flag("include-instrument").Changed
-	data, err := flows.DepotPosition(cfg, depotID, positionID, includeInstrument)
+	data, err := flows.DepotPosition(client, token, depotID, positionID, includeInstrument)
 	if err != nil {
-		cmd.PrintErrln(err)
-		return
+		return err
 	}
-	handleOutput(cmd, data)
+	return output.Print(cmd, data)
 }
 
 var depotPositionsCmd = &cobra.Command{
-	Use:   "positions <depot-id>",
-	Short: "Retrieve Depot Positions",
-	Args:  cobra.ExactArgs(1),
-	Run:   depotPositions,
+	Use:               "positions <depot>",
+	Short:             "Retrieve Depot Positions",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completion.Depots,
+	RunE:              depotPositions,
 }
 
-func depotPositions(cmd *cobra.Command, args []string) {
-	cfg := config.Get()
-	depotID := args[0]
-	includeInstrument := cmd.Flag("include-instrument").Changed
-	excludeDepot := cmd.Flag("exclude-depot").Changed
-	data, err := flows.DepotPositions(cfg, depotID, includeInstrument, excludeDepot)
+func depotPositions(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+	list, err := listing.OptionsFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	client, token, err := flows.Bootstrap(cfg)
 	if err != nil {
-		cmd.PrintErrln(err)
-		return
+		return err
 	}
-	handleOutput(cmd, data)
+	depotID, err := flows.ResolveDepotID(cfg, client, token, args[0])
+	if err != nil {
+		return err
+	}
+	includeInstrument := cmd.Flag("include-instrument").Changed
+	excludeDepot := cmd.Flag("exclude-depot").Changed
+	return output.PrintPages(cmd, flows.IndexPositions(cfg, depotID, flows.DepotPositions(client, token, depotID, includeInstrument, excludeDepot, list)))
 }
 
 var depotTransactionsCmd = &cobra.Command{
-	Use:   "transactions <depot-id>",
-	Short: "Retrieve Depot Transactions",
-	Args:  cobra.ExactArgs(1),
-	Run:   depotTransactions,
+	Use:               "transactions <depot>",
+	Short:             "Retrieve Depot Transactions",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completion.Depots,
+	RunE:              depotTransactions,
 }
 
-func depotTransactions(cmd *cobra.Command, args []string) {
-	cfg := config.Get()
-	depotID := args[0]
+func depotTransactions(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
 	wkn := cmd.Flag("wkn").Value.String()
 	isin := cmd.Flag("isin").Value.String()
 	instrumentID := cmd.Flag("instrument-id").Value.String()
 	bookingStatus := comdirect.BookingStatus(cmd.Flag("booking-status").Value.String())
-	maxBookingDateInput := cmd.Flag("max-booking-date").Value.String()
-	var maxBookingDate time.Time
-	if maxBookingDateInput != "" {
-		var err error
-		maxBookingDate, err = convert.TimeStringToTime(maxBookingDateInput)
-		if err != nil {
-			cmd.PrintErrln(err)
-			return
-		}
-	}
-	data, err := flows.DepotTransactions(cfg, depotID, wkn, isin, instrumentID, bookingStatus, maxBookingDate)
-	if err != nil {
-		cmd.PrintErrln(err)
-		return
-	}
-	handleOutput(cmd, data)
-}
-
-func handleOutput(cmd *cobra.Command, data string) {
-	output := cmd.Flag("output").Value.String()
-	slog.Info(fmt.Sprintf("Output format: %s", output))
-	switch output {
-	case "json":
-		json, err := convert.JSONToReadableJSON(data)
-		if err != nil {
-			cmd.PrintErrln(err)
-			return
-		}
-		cmd.Println(json)
-	case "yaml":
-		yaml, err := convert.JSONToYAML(data)
-		if err != nil {
-			cmd.PrintErrln(err)
-			return
-		}
-		cmd.Println(yaml)
-	default:
-		cmd.PrintErrln("Unsupported output format")
+	maxBookingDate, err := listing.EndDateFlag(cmd, "max-booking-date", time.Now())
+	if err != nil {
+		return err
+	}
+	list, err := listing.OptionsFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	client, token, err := flows.Bootstrap(cfg)
+	if err != nil {
+		return err
+	}
+	depotID, err := flows.ResolveDepotID(cfg, client, token, args[0])
+	if err != nil {
+		return err
 	}
+	return output.PrintPages(cmd, flows.DepotTransactions(client, token, depotID, wkn, isin, instrumentID, bookingStatus, maxBookingDate, list))
 }
 
 func init() {
+	listing.AddFlags(depotsCmd)
+
 	depotPositionCmd.Flags().Bool("include-instrument", false, "Include Instrument Information")
 
 	depotPositionsCmd.Flags().Bool("include-instrument", false, "Include Instrument Information")
 	depotPositionsCmd.Flags().Bool("exclude-depot", false, "Exclude Depot Information")
+	listing.AddFlags(depotPositionsCmd)
 
 	depotTransactionsCmd.Flags().String("wkn", "", "WKN")
 	depotTransactionsCmd.Flags().String("isin", "", "ISIN")
 	depotTransactionsCmd.Flags().String("instrument-id", "", "Instrument ID")
 	depotTransactionsCmd.Flags().String("booking-status", "", "Booking Status")
-	depotTransactionsCmd.Flags().String("max-booking-date", "", "Max Booking Date e.g. 2006-01-02, 2006/01/02, 01/02/2006, 02.01.2006, 02.01.06")
+	depotTransactionsCmd.Flags().String("max-booking-date", "", "Max Booking Date, the last day of the date, "+convert.DateExpressions)
+	listing.AddFlags(depotTransactionsCmd)
+	completion.Flag(depotTransactionsCmd, "wkn", completion.WKNs)
+	completion.Flag(depotTransactionsCmd, "isin", completion.ISINs)
+	completion.Flag(depotTransactionsCmd, "booking-status", completion.Values(string(comdirect.BookingStatusBooked), string(comdirect.BookingStatusNotBooked), string(comdirect.BookingStatusBoth)))
+	listing.AddDateFlags(depotTransactionsCmd)
 }
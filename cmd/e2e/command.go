@@ -14,39 +14,53 @@ func Command() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "e2e",
 		Short: "Run the end to end test",
-		Run: func(cmd *cobra.Command, args []string) {
-			e2e()
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return e2e(cmd.Flag("record").Value.String())
 		},
 	}
+	cmd.Flags().String("record", "", "Record all interactions as redacted fixtures into the given directory")
 	return cmd
 }
 
 // e2e test
-func e2e() {
+func e2e(recordDir string) error {
 	slog.SetLogLoggerLevel(slog.LevelDebug)
 
-	cfg := config.Get()
-	client, token, err := flows.Bootstrap(cfg)
+	var opts []comdirect.Option
+	if recordDir != "" {
+		recorder, err := comdirect.NewRecorder(recordDir, nil)
+		if err != nil {
+			return err
+		}
+		slog.Info(fmt.Sprintf("Recording interactions to %s", recordDir))
+		opts = append(opts, comdirect.WithTransport(recorder))
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+	client, token, err := flows.Bootstrap(cfg, opts...)
 	if err != nil {
-		panic(err)
+		return err
 	}
 
 	// Get account balances
 	slog.Info("Getting account balances")
 	accountBalances, err := client.AccountBalances(token, nil)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	slog.Info(fmt.Sprintf("Account balances: %v", accountBalances))
+	slog.Info("Account balances", "accountBalances", accountBalances)
 
 	// Get account balance
 	slog.Info("Getting account balance")
 	relevantAccountID := accountBalances.Values[1].AccountID
 	accountBalance, err := client.AccountBalance(token, relevantAccountID)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	slog.Info(fmt.Sprintf("Account balance: %v", accountBalance))
+	slog.Info("Account balance", "accountBalance", accountBalance)
 
 	// Get transactions
 	slog.Info("Getting transactions")
@@ -55,78 +69,75 @@ func e2e() {
 	}
 	transactions, err := client.AccountTransactions(token, relevantAccountID, options)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	slog.Info(fmt.Sprintf("Transactions: %v", transactions))
+	slog.Info("Transactions", "transactions", transactions)
 
 	// Get paginated transactions
 	slog.Info("Getting paginated transactions")
 	paginatedTransactions, err := client.PaginatedAccountTransactions(token, relevantAccountID, 60, nil)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	slog.Info(fmt.Sprintf("Paginated transactions: %v", paginatedTransactions))
+	slog.Info("Paginated transactions", "paginatedTransactions", paginatedTransactions)
 
 	// Get depots
 	slog.Info("Getting depots")
 	depots, err := client.Depots(token, nil)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	slog.Info(fmt.Sprintf("Depots: %v", depots))
+	slog.Info("Depots", "depots", depots)
 
 	// Get paginated depots
 	slog.Info("Getting paginated depots")
 	paginatedDepots, err := client.PaginatedDepots(token, 60)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	slog.Info(fmt.Sprintf("Paginated depots: %v", paginatedDepots))
+	slog.Info("Paginated depots", "paginatedDepots", paginatedDepots)
 
 	// Get depot positions
 	slog.Info("Getting depot positions")
 	depotPositions, err := client.DepotPositions(token, depots.Values[0].DepotID, nil)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	slog.Info(fmt.Sprintf("Depot positions: %v", depotPositions))
+	slog.Info("Depot positions", "depotPositions", depotPositions)
 
 	// Get Paginated depot positions
 	slog.Info("Getting paginated depot positions")
 	paginatedDepotPositions, err := client.PaginatedDepotPositions(token, depots.Values[0].DepotID, 60, nil)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	slog.Info(fmt.Sprintf("Paginated depot positions: %v", paginatedDepotPositions))
+	slog.Info("Paginated depot positions", "paginatedDepotPositions", paginatedDepotPositions)
 
 	// Get depot position
 	slog.Info("Getting depot position")
 	depotPosition, err := client.DepotPosition(token, depotPositions.Values[0].DepotID, depotPositions.Values[0].PositionID, nil)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	slog.Info(fmt.Sprintf("Depot position: %v", depotPosition))
+	slog.Info("Depot position", "depotPosition", depotPosition)
 
 	// Get depot transactions
 	slog.Info("Getting depot transactions")
 	depotTransactions, err := client.DepotTransactions(token, depots.Values[0].DepotID, nil)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	slog.Info(fmt.Sprintf("Depot transactions: %v", depotTransactions))
+	slog.Info("Depot transactions", "depotTransactions", depotTransactions)
 
 	// Get paginated depot transactions
 	slog.Info("Getting paginated depot transactions")
 	paginatedDepotTransactions, err := client.PaginatedDepotTransactions(token, depots.Values[0].DepotID, 60, nil)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	slog.Info(fmt.Sprintf("Paginated depot transactions: %v", paginatedDepotTransactions))
+	slog.Info("Paginated depot transactions", "paginatedDepotTransactions", paginatedDepotTransactions)
 
 	// Revoke token
 	slog.Info("Revoking token")
-	err = client.RevokeToken(token)
-	if err != nil {
-		panic(err)
-	}
+	return client.RevokeToken(token)
 }
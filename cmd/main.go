@@ -1,26 +1,51 @@
 package main
 
 import (
+	"os"
+
 	"github.com/fbufler/comdirect/cmd/account"
+	"github.com/fbufler/comdirect/cmd/configcmd"
 	"github.com/fbufler/comdirect/cmd/depot"
 	"github.com/fbufler/comdirect/cmd/e2e"
+	"github.com/fbufler/comdirect/cmd/profiles"
+	"github.com/fbufler/comdirect/cmd/session"
+	"github.com/fbufler/comdirect/config"
+	"github.com/fbufler/comdirect/internal/completion"
+	"github.com/fbufler/comdirect/internal/exit"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "comdirect",
 	Short: "comdirect is a Go client for the comdirect API",
+	Long: `comdirect is a Go client for the comdirect API
+
+Exit codes: 0 success, 1 other errors, 2 config error, 3 authentication or TAN required,
+4 API error, 5 rate limited, 6 not found.`,
+	// errors are printed by exit.Handle, the usage only for invalid arguments and flags
+	SilenceErrors: true,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		cmd.SilenceUsage = true
+	},
 }
 
 func init() {
 	rootCmd.AddCommand(e2e.Command())
 	rootCmd.AddCommand(account.Command())
 	rootCmd.AddCommand(depot.Command())
+	rootCmd.AddCommand(profiles.Command())
+	rootCmd.AddCommand(configcmd.Command())
+	rootCmd.AddCommand(session.Command())
+	rootCmd.AddCommand(session.LoginCommand())
+	rootCmd.AddCommand(session.LogoutCommand())
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Enable verbose output")
-	viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
+	config.BindFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
+	rootCmd.PersistentFlags().StringP("profile", "p", "", "Profile to use (default \"default\", env COMDIRECT_PROFILE)")
+	config.BindFlag("profile", rootCmd.PersistentFlags().Lookup("profile"))
+	completion.Flag(rootCmd, "profile", completion.Profiles)
+	exit.AddFlags(rootCmd)
 }
 
 func main() {
-	rootCmd.Execute()
+	os.Exit(exit.Handle(rootCmd, rootCmd.Execute()))
 }
@@ -0,0 +1,38 @@
+package profiles
+
+import (
+	"github.com/fbufler/comdirect/config"
+	"github.com/fbufler/comdirect/internal/flows"
+	"github.com/fbufler/comdirect/internal/output"
+	"github.com/spf13/cobra"
+)
+
+func Command() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "profiles",
+		Short: "Manage Profiles",
+	}
+
+	output.AddFlags(cmd)
+	cmd.AddCommand(listCmd)
+
+	return cmd
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List Profiles and their Sessions",
+	RunE:  list,
+}
+
+func list(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+	data, err := flows.Profiles(cfg)
+	if err != nil {
+		return err
+	}
+	return output.Print(cmd, data)
+}
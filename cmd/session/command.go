@@ -0,0 +1,85 @@
+package session
+
+import (
+	"github.com/fbufler/comdirect/config"
+	"github.com/fbufler/comdirect/internal/flows"
+	"github.com/fbufler/comdirect/internal/output"
+	"github.com/spf13/cobra"
+)
+
+func Command() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "session",
+		Short: "Inspect the Session",
+	}
+
+	output.AddFlags(cmd)
+	cmd.AddCommand(statusCmd)
+
+	return cmd
+}
+
+func LoginCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: "Start a Session and keep it in the Token Cache",
+		RunE:  login,
+	}
+	output.AddFlags(cmd)
+	cmd.Flags().Bool("force", false, "Start a new session even if a live session is cached")
+	return cmd
+}
+
+func LogoutCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "logout",
+		Short: "Revoke the Session and wipe the Token Cache",
+		RunE:  logout,
+	}
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the Status of the cached Session",
+	Long: "Show the Status of the cached Session\n\n" +
+		"A refresh token is valid for 20 minutes, every refresh starts this lifetime again. If the token endpoint sends\n" +
+		"refresh_token_expires_in, its lifetime is used instead.",
+	RunE: status,
+}
+
+func login(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+	force := cmd.Flag("force").Changed
+	data, err := flows.Login(cfg, force)
+	if err != nil {
+		return err
+	}
+	return output.Print(cmd, data)
+}
+
+func logout(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+	if err := flows.Logout(cfg); err != nil {
+		return err
+	}
+	cmd.Printf("Logged out of profile %s\n", cfg.Profile)
+	return nil
+}
+
+func status(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+	data, err := flows.Session(cfg)
+	if err != nil {
+		return err
+	}
+	return output.Print(cmd, data)
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// Setting is a single config value and where it was set.
+type Setting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// Sources of a setting.
+const (
+	SourceFlag    = "flag"
+	SourceEnv     = "env"
+	SourceFile    = "file"
+	SourceDefault = "default"
+	SourceUnset   = "unset"
+)
+
+// secretKeys are masked by Settings.
+var secretKeys = map[string]bool{
+	"client-secret":  true,
+	"pin":            true,
+	"encryption-key": true,
+}
+
+var flags = map[string]*pflag.Flag{}
+
+// BindFlag binds a flag to a key, the flag takes precedence over all other sources.
+func BindFlag(key string, flag *pflag.Flag) {
+	flags[key] = flag
+	viper.BindPFlag(key, flag)
+}
+
+// Settings returns every key of the active profile with its effective value and source.
+// The client keys show the values of the active profile, secrets are masked.
+func (c *Config) Settings() []Setting {
+	settings := []Setting{}
+	value := reflect.ValueOf(*c)
+	walkKeys("", value.Type(), func(key string, field reflect.StructField) {
+		setting := Setting{
+			Key:   key,
+			Value: fmt.Sprint(fieldByKey(value, key).Interface()),
+		}
+
+		// client keys are taken from the profile if it sets them or they differ from the client block,
+		// e.g. a pin of the client block is dropped if the profile sets a pin-command
+		sourceKey := key
+		if profileKey, ok := strings.CutPrefix(key, "client."); ok && c.Profile != DefaultProfile {
+			profileKey = "profiles." + c.Profile + "." + profileKey
+			defaultValue := fmt.Sprint(fieldByKey(reflect.ValueOf(c.defaultClient), strings.TrimPrefix(key, "client.")).Interface())
+			if source(profileKey) != SourceUnset || setting.Value != defaultValue {
+				sourceKey = profileKey
+			}
+		}
+		setting.Source = source(sourceKey)
+		if setting.Source == SourceEnv {
+			setting.Source = fmt.Sprintf("%s (%s)", SourceEnv, EnvName(sourceKey))
+		} else if sourceKey != key {
+			setting.Source = fmt.Sprintf("%s (%s)", setting.Source, sourceKey)
+		}
+		if secretKeys[field.Tag.Get("mapstructure")] && setting.Value != "" {
+			setting.Value = "********"
+		}
+		settings = append(settings, setting)
+	})
+	return settings
+}
+
+// source returns where the value of a key is taken from, following the precedence of viper.
+func source(key string) string {
+	if flag, ok := flags[key]; ok && flag.Changed {
+		return SourceFlag
+	}
+	if _, ok := os.LookupEnv(EnvName(key)); ok {
+		return SourceEnv
+	}
+	if viper.InConfig(key) {
+		return SourceFile
+	}
+	if _, ok := defaults[key]; ok {
+		return SourceDefault
+	}
+	return SourceUnset
+}
+
+// fieldByKey returns the struct field of a key as returned by walkKeys.
+func fieldByKey(value reflect.Value, key string) reflect.Value {
+	for _, name := range strings.Split(key, ".") {
+		for i := 0; i < value.NumField(); i++ {
+			if value.Type().Field(i).Tag.Get("mapstructure") == name {
+				value = value.Field(i)
+				break
+			}
+		}
+	}
+	return value
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// envPrefix prefixes all environment variables, e.g. COMDIRECT_CLIENT_PIN for client.pin.
+const envPrefix = "COMDIRECT"
+
+var envKeyReplacer = strings.NewReplacer(".", "_", "-", "_")
+
+// EnvName returns the environment variable a key can be set with.
+func EnvName(key string) string {
+	return envPrefix + "_" + strings.ToUpper(envKeyReplacer.Replace(key))
+}
+
+// bindEnvs binds the environment variable of every key of the struct type.
+// viper only considers environment variables of keys it knows when unmarshaling,
+// so keys which are neither in the config file nor have a default would be missed otherwise.
+func bindEnvs(prefix string, t reflect.Type) {
+	walkKeys(prefix, t, func(key string, _ reflect.StructField) {
+		viper.BindEnv(key)
+	})
+}
+
+// walkKeys calls fn for every leaf key of the struct type, nested structs are walked as well and maps are skipped.
+func walkKeys(prefix string, t reflect.Type, fn func(key string, field reflect.StructField)) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("mapstructure")
+		if !field.IsExported() || tag == "" {
+			continue
+		}
+		key := tag
+		if prefix != "" {
+			key = prefix + "." + tag
+		}
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			walkKeys(key, field.Type, fn)
+		case reflect.Map:
+		default:
+			fn(key, field)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrConfig is matched by every error caused by the config, e.g. a missing setting, an unknown profile or a failing secret command.
+// The message of the error is kept, use errors.Is to check for it.
+var ErrConfig = errors.New("config error")
+
+type configError struct {
+	err error
+}
+
+func (e *configError) Error() string {
+	return e.err.Error()
+}
+
+func (e *configError) Unwrap() []error {
+	return []error{e.err, ErrConfig}
+}
+
+// Errorf formats an error like fmt.Errorf and marks it as config error, see ErrConfig.
+func Errorf(format string, a ...any) error {
+	return &configError{err: fmt.Errorf(format, a...)}
+}
+
+// wrapError marks err as config error, nil is returned unchanged.
+func wrapError(err error) error {
+	if err == nil || errors.Is(err, ErrConfig) {
+		return err
+	}
+	return &configError{err: err}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/template"
+)
+
+var fileTemplate = template.Must(template.New("config").Funcs(template.FuncMap{"quote": strconv.Quote}).Parse(`# comdirect CLI configuration, see the README for all options.
+client:
+  # credentials of your API access, shown in the online banking after activating the API
+  client-id: {{ quote .Client.ClientID }}
+{{- if .Client.ClientSecret }}
+  client-secret: {{ quote .Client.ClientSecret }}
+{{- else }}
+  # read the client secret from a file or the output of a command instead of storing it here
+  # client-secret-file: "/path/to/client-secret"
+  # client-secret-command: "pass show comdirect/client-secret"
+{{- end }}
+  # the Zugangsnummer you use to log in to the online banking
+  zugangsnummer: {{ quote .Client.Zugangsnummer }}
+{{- if .Client.Pin }}
+  pin: {{ quote .Client.Pin }}
+{{- else }}
+  # the pin is prompted for if none is configured, or use one of
+  # pin-file: "/path/to/pin"
+  # pin-command: "pass show comdirect/pin"
+{{- end }}
+  # names for accounts and depots, usable instead of their IDs
+  # aliases:
+  #   giro: "DE12345678901234567890"
+  #   depot: "123456789"
+
+# additional logins can be configured as profiles and selected with --profile
+# profiles:
+#   company:
+#     client-id: "company-client-id"
+#     zugangsnummer: "company-zugangsnummer"
+
+cli:
+  # cache the session between runs, so a TAN is only needed once per session
+  enable-cache: {{ .Cli.EnableCache }}
+{{- if .Cli.EncryptionKey }}
+  # passphrase the cache is encrypted with, at least 16 characters
+  encryption-key: {{ quote .Cli.EncryptionKey }}
+{{- end }}
+`))
+
+// DefaultFile returns the path config init writes to, the config directory in the home of the user.
+func DefaultFile() string {
+	if os.Getenv("OS") == "Windows_NT" {
+		return filepath.Join(os.Getenv("APPDATA"), "comdirect", "config.yaml")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "config.yaml"
+	}
+	return filepath.Join(home, ".comdirect", "config.yaml")
+}
+
+// WriteFile writes a commented config file with the given values, which is only readable by the current user.
+// An existing file is only replaced if overwrite is set.
+func WriteFile(path string, config *Config, overwrite bool) error {
+	if _, err := os.Stat(path); err == nil && !overwrite {
+		return fmt.Errorf("config file %s already exists", path)
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	// the mode of OpenFile only applies to new files
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return err
+	}
+	if err := fileTemplate.Execute(f, config); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
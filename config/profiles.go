@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+)
+
+// DefaultProfile is the name of the top level client block.
+const DefaultProfile = "default"
+
+// UseProfile makes the named profile the active client config.
+// An empty name selects the default profile.
+func (c *Config) UseProfile(name string) error {
+	if name == "" {
+		name = DefaultProfile
+	}
+	client, err := c.ProfileClient(name)
+	if err != nil {
+		return err
+	}
+	c.Client = client
+	c.Profile = name
+	return nil
+}
+
+// ProfileClient returns the client config of the named profile.
+// Values not set in the profile are taken from the top level client block.
+func (c *Config) ProfileClient(name string) (ClientConfig, error) {
+	profile, ok := c.Profiles[name]
+	if !ok && name == DefaultProfile {
+		return c.defaultClient, nil
+	}
+	if !ok {
+		return ClientConfig{}, fmt.Errorf("profile %q is not configured", name)
+	}
+	return mergeClientConfig(c.defaultClient, profile), nil
+}
+
+// ProfileNames returns the default profile followed by the configured profiles in alphabetical order.
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles)+1)
+	for name := range c.Profiles {
+		if name != DefaultProfile {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfile}, names...)
+}
+
+func mergeClientConfig(base ClientConfig, profile ClientConfig) ClientConfig {
+	merged := base
+	setIfNotEmpty(&merged.APIURL, profile.APIURL)
+	setIfNotEmpty(&merged.TokenURL, profile.TokenURL)
+	setIfNotEmpty(&merged.RevokeTokenURL, profile.RevokeTokenURL)
+	setIfNotEmpty(&merged.ClientID, profile.ClientID)
+	setIfNotEmpty(&merged.Zugangsnummer, profile.Zugangsnummer)
+	// a secret source of the profile replaces all sources of the top level block,
+	// otherwise a plain value of the top level block would take precedence over e.g. a command of the profile
+	if profile.ClientSecret != "" || profile.ClientSecretFile != "" || profile.ClientSecretCommand != "" {
+		merged.ClientSecret = profile.ClientSecret
+		merged.ClientSecretFile = profile.ClientSecretFile
+		merged.ClientSecretCommand = profile.ClientSecretCommand
+	}
+	if profile.Pin != "" || profile.PinFile != "" || profile.PinCommand != "" {
+		merged.Pin = profile.Pin
+		merged.PinFile = profile.PinFile
+		merged.PinCommand = profile.PinCommand
+	}
+	if len(profile.Aliases) > 0 {
+		merged.Aliases = make(map[string]string, len(base.Aliases)+len(profile.Aliases))
+		maps.Copy(merged.Aliases, base.Aliases)
+		maps.Copy(merged.Aliases, profile.Aliases)
+	}
+	return merged
+}
+
+func setIfNotEmpty(target *string, value string) {
+	if value != "" {
+		*target = value
+	}
+}
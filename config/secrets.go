@@ -0,0 +1,71 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ResolveSecrets returns a copy of the client config with the client secret and pin read from their file or command.
+// A plain value takes precedence over a file, a file over a command.
+// The resolved secrets are only kept in memory, a pin which is not configured at all stays empty.
+func (c ClientConfig) ResolveSecrets() (ClientConfig, error) {
+	clientSecret, err := resolveSecret("client-secret", c.ClientSecret, c.ClientSecretFile, c.ClientSecretCommand)
+	if err != nil {
+		return c, wrapError(err)
+	}
+	pin, err := resolveSecret("pin", c.Pin, c.PinFile, c.PinCommand)
+	if err != nil {
+		return c, wrapError(err)
+	}
+	c.ClientSecret = clientSecret
+	c.Pin = pin
+	return c, nil
+}
+
+func resolveSecret(key string, value string, file string, command string) (string, error) {
+	switch {
+	case value != "":
+		return value, nil
+	case file != "":
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("unable to read %s-file: %w", key, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case command != "":
+		secret, err := runSecretCommand(command)
+		if err != nil {
+			return "", fmt.Errorf("unable to run %s-command: %w", key, err)
+		}
+		return secret, nil
+	}
+	return "", nil
+}
+
+// runSecretCommand runs the command in a shell and returns the first line of its output.
+// Stdin and stderr are passed through, so the command can ask for e.g. a gpg passphrase.
+func runSecretCommand(command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	var stdout bytes.Buffer
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+	secret, _, _ := strings.Cut(stdout.String(), "\n")
+	secret = strings.TrimRight(secret, "\r")
+	if secret == "" {
+		return "", fmt.Errorf("command returned no output")
+	}
+	return secret, nil
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+const minEncryptionKeyLength = 16
+
+// Validate checks the cli settings and every profile, the returned error lists all problems found.
+func (c *Config) Validate() error {
+	errs := []error{c.validateCli()}
+	for _, name := range c.ProfileNames() {
+		// the client block may be left empty if only named profiles are used
+		if name == DefaultProfile && c.defaultClient.ClientID == "" && c.defaultClient.Zugangsnummer == "" && len(c.Profiles) > 0 {
+			continue
+		}
+		errs = append(errs, c.validateProfile(name))
+	}
+	return wrapError(noFileHint(errors.Join(errs...)))
+}
+
+func (c *Config) validateCli() error {
+	if !c.Cli.EnableCache {
+		return nil
+	}
+	if c.Cli.EncryptionKey == "" {
+		return errors.New("cli.encryption-key must be set if cli.enable-cache is set")
+	}
+	// the encryption key is a passphrase, the AES key is derived from it
+	if len(c.Cli.EncryptionKey) < minEncryptionKeyLength {
+		return fmt.Errorf("cli.encryption-key must be at least %d characters long, got %d", minEncryptionKeyLength, len(c.Cli.EncryptionKey))
+	}
+	return nil
+}
+
+// validateProfile checks the client config of a profile, values missing in a profile are looked up in the client block.
+func (c *Config) validateProfile(name string) error {
+	client, err := c.ProfileClient(name)
+	if err != nil {
+		return err
+	}
+
+	prefix := "client"
+	if _, ok := c.Profiles[name]; ok {
+		prefix = "profiles." + name
+	}
+
+	var errs []error
+	missing := func(key string, value string) {
+		if value == "" {
+			errs = append(errs, fmt.Errorf("%s.%s is not set", prefix, key))
+		}
+	}
+	invalidURL := func(key string, value string) {
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("%s.%s is not a valid URL: %q", prefix, key, value))
+		}
+	}
+
+	invalidURL("api-url", client.APIURL)
+	invalidURL("token-url", client.TokenURL)
+	invalidURL("revoke-token-url", client.RevokeTokenURL)
+	missing("client-id", client.ClientID)
+	missing("zugangsnummer", client.Zugangsnummer)
+	if client.ClientSecret == "" && client.ClientSecretFile == "" && client.ClientSecretCommand == "" {
+		errs = append(errs, fmt.Errorf("%s.client-secret, client-secret-file or client-secret-command must be set", prefix))
+	}
+
+	return errors.Join(errs...)
+}
@@ -1,20 +1,32 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 
 	"github.com/spf13/viper"
 )
 
 type ClientConfig struct {
-	APIURL         string `mapstructure:"api-url"`
-	TokenURL       string `mapstructure:"token-url"`
-	RevokeTokenURL string `mapstructure:"revoke-token-url"`
-	ClientID       string `mapstructure:"client-id"`
-	ClientSecret   string `mapstructure:"client-secret"`
-	Zugangsnummer  string `mapstructure:"zugangsnummer"`
-	Pin            string `mapstructure:"pin"`
+	APIURL              string `mapstructure:"api-url"`
+	TokenURL            string `mapstructure:"token-url"`
+	RevokeTokenURL      string `mapstructure:"revoke-token-url"`
+	ClientID            string `mapstructure:"client-id"`
+	ClientSecret        string `mapstructure:"client-secret"`
+	ClientSecretFile    string `mapstructure:"client-secret-file"`
+	ClientSecretCommand string `mapstructure:"client-secret-command"`
+	Zugangsnummer       string `mapstructure:"zugangsnummer"`
+	Pin                 string `mapstructure:"pin"`
+	PinFile             string `mapstructure:"pin-file"`
+	PinCommand          string `mapstructure:"pin-command"`
+	// Aliases map names to account or depot identifiers, e.g. giro to an IBAN
+	Aliases map[string]string `mapstructure:"aliases"`
 }
 
 type CliConfig struct {
@@ -24,66 +36,142 @@ type CliConfig struct {
 }
 
 type Config struct {
-	Client  ClientConfig `mapstructure:"client"`
-	Cli     CliConfig    `mapstructure:"cli"`
-	Verbose bool         `mapstructure:"verbose"`
+	Client   ClientConfig            `mapstructure:"client"`
+	Profiles map[string]ClientConfig `mapstructure:"profiles"`
+	Profile  string                  `mapstructure:"profile"`
+	Cli      CliConfig               `mapstructure:"cli"`
+	Verbose  bool                    `mapstructure:"verbose"`
+
+	// defaultClient is the top level client block, profiles fall back to it
+	defaultClient ClientConfig
+}
+
+// defaults are used for keys which are neither set by flag, environment nor config file.
+var defaults = map[string]any{
+	"profile":                 DefaultProfile,
+	"client.api-url":          "https://api.comdirect.de/api",
+	"client.token-url":        "https://api.comdirect.de/oauth/token",
+	"client.revoke-token-url": "https://api.comdirect.de/oauth/revoke",
+	"cli.enable-cache":        false,
+	"cli.storage-path":        cliStoragePath(),
+	"verbose":                 false,
 }
 
 func setDefaults() {
-	viper.SetDefault("client.api-url", "https://api.comdirect.de/api")
-	viper.SetDefault("client.token-url", "https://api.comdirect.de/oauth/token")
-	viper.SetDefault("client.revoke-token-url", "https://api.comdirect.de/oauth/revoke")
-	viper.SetDefault("cli.enable-cache", false)
-	viper.SetDefault("cli.storage-path", cliStoragePath())
+	for key, value := range defaults {
+		viper.SetDefault(key, value)
+	}
 }
 
 func cliStoragePath() string {
-	if cfg.Cli.StoragePath != "" {
-		return cfg.Cli.StoragePath
-	}
 	if os.Getenv("OS") == "Windows_NT" {
 		return windowsCliStoragePath()
 	}
 	return unixCliStoragePath()
 }
 
+// windowsCliStoragePath returns the local app data directory.
 func windowsCliStoragePath() string {
-	return os.TempDir() + "\\token-cache"
+	if dir := os.Getenv("LOCALAPPDATA"); dir != "" {
+		return filepath.Join(dir, "comdirect")
+	}
+	return filepath.Join(os.TempDir(), "comdirect")
 }
 
+// unixCliStoragePath returns the state directory following the XDG base directory specification.
 func unixCliStoragePath() string {
-	return os.TempDir() + "/token-cache"
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "comdirect")
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local", "state", "comdirect")
+	}
+	return filepath.Join(os.TempDir(), "comdirect")
 }
 
-var cfg Config = Config{}
+var (
+	cfg      *Config
+	loadErr  error
+	loadOnce sync.Once
+)
 
 func init() {
 	viper.SetConfigType("yaml")
 	viper.SetConfigName("config")
-	configPaths := configPaths()
-	for _, path := range configPaths {
+	for _, path := range configPaths() {
 		viper.AddConfigPath(path)
 	}
+	viper.SetEnvPrefix(envPrefix)
+	viper.SetEnvKeyReplacer(envKeyReplacer)
 	viper.AutomaticEnv()
+	bindEnvs("", reflect.TypeOf(Config{}))
 	setDefaults()
+}
+
+// load reads the config file, it is only called once by Load.
+// The config file is optional, every key can be set with an environment variable instead.
+func load() (*Config, error) {
 	err := viper.ReadInConfig()
+	var notFound viper.ConfigFileNotFoundError
+	if errors.As(err, &notFound) {
+		slog.Debug(fmt.Sprintf("No config file found in %s, using environment variables only", strings.Join(configPaths(), ", ")))
+		err = nil
+	}
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("unable to read config file: %w", err)
+	}
+	for name := range viper.GetStringMap("profiles") {
+		bindEnvs("profiles."+name, reflect.TypeOf(ClientConfig{}))
 	}
-	err = viper.Unmarshal(&cfg)
+
+	config := &Config{}
+	if err := viper.UnmarshalExact(config); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", viper.ConfigFileUsed(), err)
+	}
+	config.defaultClient = config.Client
+	return config, nil
+}
+
+// Load loads the config on first use and selects the profile set with --profile or COMDIRECT_PROFILE.
+func Load() (*Config, error) {
+	loadOnce.Do(func() {
+		cfg, loadErr = load()
+	})
+	if loadErr != nil {
+		return nil, wrapError(loadErr)
+	}
+	if err := cfg.UseProfile(viper.GetString("profile")); err != nil {
+		return nil, wrapError(err)
+	}
+	return cfg, nil
+}
+
+// Get returns the config like Load, the selected profile is validated as well, see Validate.
+func Get() (*Config, error) {
+	cfg, err := Load()
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
+	if err := cfg.validateProfile(cfg.Profile); err != nil {
+		return nil, wrapError(noFileHint(err))
+	}
+	if err := cfg.validateCli(); err != nil {
+		return nil, wrapError(noFileHint(err))
+	}
+	return cfg, nil
+}
 
-	if cfg.Cli.EnableCache {
-		if cfg.Cli.EncryptionKey == "" {
-			panic("encryption key must be set if cache is enabled")
-		}
-		// AES encryption key must be 128, 192, or 256 bits long
-		if len(cfg.Cli.EncryptionKey) != 16 && len(cfg.Cli.EncryptionKey) != 24 && len(cfg.Cli.EncryptionKey) != 32 {
-			panic(fmt.Sprintf("encryption key must be 128, 192, or 256 bits long, got %d", len(cfg.Cli.EncryptionKey)))
-		}
+// noFileHint adds a hint how to create a config file to err, if no config file was found.
+func noFileHint(err error) error {
+	if err == nil || File() != "" {
+		return err
 	}
+	return errors.Join(err, fmt.Errorf("no config file found in %s, run 'comdirect config init' to create one or set the COMDIRECT_ environment variables", strings.Join(configPaths(), ", ")))
+}
+
+// File returns the path of the loaded config file, it is empty if the config is only set by environment variables.
+func File() string {
+	return viper.ConfigFileUsed()
 }
 
 func configPaths() []string {
@@ -96,7 +184,7 @@ func configPaths() []string {
 func windowsConfigPaths() []string {
 	return []string{
 		"C:\\ProgramData\\comdirect",
-		"C:\\Users\\%USERNAME%\\AppData\\Roaming\\comdirect",
+		filepath.Join(os.Getenv("APPDATA"), "comdirect"),
 		".",
 	}
 }
@@ -108,6 +196,3 @@ func unixConfigPaths() []string {
 		".",
 	}
 }
-func Get() *Config {
-	return &cfg
-}
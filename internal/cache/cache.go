@@ -3,63 +3,74 @@ package cache
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"os"
+	"path/filepath"
 
 	"github.com/fbufler/comdirect/pkg/comdirect"
 )
 
+// Cache is the token cache of a single login, backed by an encrypted token store.
 type Cache struct {
 	encryptionKey string
-	storagePath   string
+	store         *comdirect.EncryptedFileTokenStore
+	key           string
 }
 
-func NewCache(storagePath string, encryptionKey string) *Cache {
+func NewCache(dir string, key string, encryptionKey string) *Cache {
 	return &Cache{
 		encryptionKey: encryptionKey,
-		storagePath:   storagePath,
+		store:         comdirect.NewEncryptedFileTokenStore(dir, encryptionKey),
+		key:           key,
 	}
 }
 
 func (c *Cache) Load() (*comdirect.AuthToken, error) {
 	slog.Debug("Loading token")
-	data, err := os.ReadFile(c.storagePath)
-	if err != nil {
-		return nil, err
+	token, err := c.store.Load(c.key)
+	if errors.Is(err, comdirect.ErrUnknownTokenFormat) {
+		return c.migrate()
 	}
-
-	token, err := c.decrypt(string(data))
-	if err != nil {
+	if errors.Is(err, comdirect.ErrTokenTampered) {
 		slog.Warn("Failed to decrypt token")
-		return nil, err
 	}
-
-	return token, nil
+	return token, err
 }
 
 func (c *Cache) Save(token *comdirect.AuthToken) error {
 	slog.Debug("Storing token")
-	encryptedToken, err := c.encrypt(token)
-	if err != nil {
-		return err
-	}
+	return c.store.Save(c.key, token)
+}
 
-	return os.WriteFile(c.storagePath, []byte(encryptedToken), 0600)
+// Delete removes the token from the cache.
+func (c *Cache) Delete() error {
+	slog.Debug("Deleting token")
+	return c.store.Delete(c.key)
 }
 
-func (c *Cache) encrypt(token *comdirect.AuthToken) (string, error) {
-	slog.Debug("Encrypting token")
-	serializedToken, err := json.Marshal(token)
+// migrate loads a cache written in the legacy XOR format and stores it in the current format.
+func (c *Cache) migrate() (*comdirect.AuthToken, error) {
+	slog.Info("Migrating token cache to the current format")
+	data, err := os.ReadFile(c.store.Path(c.key))
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := c.legacyDecrypt(string(data))
 	if err != nil {
-		return "", err
+		slog.Warn("Failed to decrypt legacy token")
+		return nil, err
 	}
 
-	encryptedToken := xorEncrypt(serializedToken, c.encryptionKey)
-	return base64.StdEncoding.EncodeToString(encryptedToken), nil
+	if err := c.Save(token); err != nil {
+		return nil, err
+	}
+
+	return token, nil
 }
 
-func (c *Cache) decrypt(data string) (*comdirect.AuthToken, error) {
-	slog.Debug("Decrypting token")
+func (c *Cache) legacyDecrypt(data string) (*comdirect.AuthToken, error) {
 	decodedData, err := base64.StdEncoding.DecodeString(data)
 	if err != nil {
 		return nil, err
@@ -84,3 +95,26 @@ func xorEncrypt(data []byte, key string) []byte {
 	}
 	return encrypted
 }
+
+// writeFileAtomic writes the data to a temporary file with strict permissions and renames it,
+// so readers never see a partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), ".cache-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fbufler/comdirect/internal/secretbox"
+	"github.com/fbufler/comdirect/pkg/comdirect"
+)
+
+// Index is the cached list of accounts and depots of a login.
+// It is used to resolve identifiers like IBANs without requesting the lists, so it may be outdated.
+type Index struct {
+	UpdatedAt time.Time           `json:"updatedAt"`
+	Accounts  []comdirect.Account `json:"accounts"`
+	Depots    []comdirect.Depot   `json:"depots"`
+	// Positions are the positions by depot ID, ISIN and Name are only known if the instrument was requested
+	Positions map[string][]Position `json:"positions,omitempty"`
+}
+
+// Position holds the identifiers of a depot position, used for completion.
+type Position struct {
+	PositionID string `json:"positionId"`
+	WKN        string `json:"wkn"`
+	ISIN       string `json:"isin,omitempty"`
+	Name       string `json:"name,omitempty"`
+}
+
+// indexMagic prefixes every index file, it is encrypted like the token files and shares their derived key, see secretbox.
+var indexMagic = []byte("CDIX")
+
+var errIndexTampered = errors.New("cached index has been tampered with or the encryption key is wrong")
+
+// LoadIndex returns the cached index, or an empty index if none is cached yet.
+func (c *Cache) LoadIndex() (*Index, error) {
+	slog.Debug("Loading index")
+	data, err := os.ReadFile(c.indexPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return &Index{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := secretbox.Open(c.encryptionKey, indexMagic, data)
+	if errors.Is(err, secretbox.ErrUnknownFormat) || errors.Is(err, secretbox.ErrTampered) {
+		return nil, errIndexTampered
+	}
+	if err != nil {
+		return nil, err
+	}
+	var index Index
+	if err := json.Unmarshal(plaintext, &index); err != nil {
+		return nil, err
+	}
+	return &index, nil
+}
+
+// SaveIndex stores the index, the file is replaced atomically.
+func (c *Cache) SaveIndex(index *Index) error {
+	slog.Debug("Storing index")
+	plaintext, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	data, err := secretbox.Seal(c.encryptionKey, indexMagic, plaintext)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(c.indexPath(), data)
+}
+
+// DeleteIndex removes the cached index.
+func (c *Cache) DeleteIndex() error {
+	err := os.Remove(c.indexPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// indexPath returns the path of the index next to the token of the cache.
+func (c *Cache) indexPath() string {
+	return strings.TrimSuffix(c.store.Path(c.key), ".token") + ".index"
+}
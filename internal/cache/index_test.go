@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/fbufler/comdirect/pkg/comdirect"
+)
+
+func TestIndexRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	indexCache := NewCache(dir, "default", testEncryptionKey)
+	index, err := indexCache.LoadIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Accounts) != 0 {
+		t.Errorf("expected an empty index, got %d accounts", len(index.Accounts))
+	}
+
+	index.Accounts = []comdirect.Account{{AccountID: "account", IBAN: "DE02120300000000202051"}}
+	if err := indexCache.SaveIndex(index); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := indexCache.LoadIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Accounts) != 1 || loaded.Accounts[0].AccountID != "account" {
+		t.Errorf("unexpected loaded accounts %+v", loaded.Accounts)
+	}
+
+	if _, err := NewCache(dir, "default", "another encryption key").LoadIndex(); err == nil {
+		t.Error("index was loaded with another encryption key")
+	}
+}
+
+// BenchmarkResolveMiss loads the token and the index and stores both again, like a command resolving an unknown identifier.
+// The token and the index share the derived key, so only the first command of a process pays for scrypt.
+func BenchmarkResolveMiss(b *testing.B) {
+	dir := b.TempDir()
+	setup := NewCache(dir, "default", testEncryptionKey)
+	if err := setup.Save(&comdirect.AuthToken{AccessToken: "access"}); err != nil {
+		b.Fatal(err)
+	}
+	if err := setup.SaveIndex(&Index{}); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c := NewCache(dir, "default", testEncryptionKey)
+		token, err := c.Load()
+		if err != nil {
+			b.Fatal(err)
+		}
+		index, err := c.LoadIndex()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := c.SaveIndex(index); err != nil {
+			b.Fatal(err)
+		}
+		if err := c.Save(token); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
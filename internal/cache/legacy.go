@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/fbufler/comdirect/config"
+)
+
+// legacyDefaultPath is the default cache file of versions which kept a single token cache instead of one per login.
+var legacyDefaultPath = filepath.Join(os.TempDir(), "token-cache")
+
+// migrateLegacyFile turns a storage path which still points to a legacy cache file into the cache directory.
+// The single cache belonged to the login of the default profile, so its token is sealed into that cache.
+func (s *Store) migrateLegacyFile() error {
+	info, err := os.Stat(s.dir)
+	if err != nil || info.IsDir() {
+		return nil
+	}
+	data, err := os.ReadFile(s.dir)
+	if err != nil {
+		return err
+	}
+
+	slog.Debug(fmt.Sprintf("Migrating the legacy token cache %s into a cache directory", s.dir))
+	// the file has to make way for the directory before the token can be stored in it
+	if err := os.Remove(s.dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+	return NewCache(s.dir, config.DefaultProfile, s.encryptionKey).sealLegacy(s.dir, data)
+}
+
+// MigrateLegacy takes over the token of a legacy cache at the old default path, if the cache holds no token yet.
+// The legacy cache is removed once migrated, one which can't be decrypted is removed as well and only logged.
+func (c *Cache) MigrateLegacy() error {
+	if _, err := os.Stat(c.store.Path(c.key)); !errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	data, err := os.ReadFile(legacyDefaultPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	slog.Debug(fmt.Sprintf("Migrating the legacy token cache %s", legacyDefaultPath))
+	if err := c.sealLegacy(legacyDefaultPath, data); err != nil {
+		return err
+	}
+	return os.Remove(legacyDefaultPath)
+}
+
+// sealLegacy stores the token of the legacy cache read from path in the cache.
+func (c *Cache) sealLegacy(path string, data []byte) error {
+	token, err := c.legacyDecrypt(string(data))
+	if err != nil {
+		slog.Warn(fmt.Sprintf("unable to migrate the legacy token cache %s: %s", path, err))
+		return nil
+	}
+	return c.Save(token)
+}
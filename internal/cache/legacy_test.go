@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fbufler/comdirect/config"
+	"github.com/fbufler/comdirect/pkg/comdirect"
+)
+
+const testEncryptionKey = "0123456789abcdef"
+
+// xorLegacyCache returns a legacy cache file in the XOR format.
+func xorLegacyCache(t *testing.T, token *comdirect.AuthToken) []byte {
+	t.Helper()
+	data, err := json.Marshal(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []byte(base64.StdEncoding.EncodeToString(xorEncrypt(data, testEncryptionKey)))
+}
+
+func TestMigrateLegacyStoragePathFile(t *testing.T) {
+	legacyDefaultPath = filepath.Join(t.TempDir(), "token-cache")
+	// the storage path still points to the legacy cache file
+	storagePath := filepath.Join(t.TempDir(), "token-cache")
+	if err := os.WriteFile(storagePath, xorLegacyCache(t, &comdirect.AuthToken{AccessToken: "legacy-access"}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// any profile may open the store first, the token belongs to the default profile
+	if _, err := NewStore(storagePath, testEncryptionKey).Cache("work"); err != nil {
+		t.Fatal(err)
+	}
+	token, err := NewCache(storagePath, config.DefaultProfile, testEncryptionKey).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "legacy-access" {
+		t.Errorf("unexpected migrated token %q", token.AccessToken)
+	}
+	entries, err := os.ReadDir(storagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only the migrated token in the cache directory, got %d entries", len(entries))
+	}
+}
+
+func TestMigrateLegacyDefaultPath(t *testing.T) {
+	legacyDefaultPath = filepath.Join(t.TempDir(), "token-cache")
+	if err := os.WriteFile(legacyDefaultPath, xorLegacyCache(t, &comdirect.AuthToken{AccessToken: "legacy-access"}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	profileCache, err := NewStore(filepath.Join(t.TempDir(), "comdirect"), testEncryptionKey).Cache("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := profileCache.MigrateLegacy(); err != nil {
+		t.Fatal(err)
+	}
+	token, err := profileCache.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "legacy-access" {
+		t.Errorf("unexpected migrated token %q", token.AccessToken)
+	}
+	if _, err := os.Stat(legacyDefaultPath); !os.IsNotExist(err) {
+		t.Errorf("legacy cache was not removed: %v", err)
+	}
+}
+
+func TestMigrateLegacyKeepsCurrentToken(t *testing.T) {
+	legacyDefaultPath = filepath.Join(t.TempDir(), "token-cache")
+	if err := os.WriteFile(legacyDefaultPath, xorLegacyCache(t, &comdirect.AuthToken{AccessToken: "legacy-access"}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	profileCache, err := NewStore(filepath.Join(t.TempDir(), "comdirect"), testEncryptionKey).Cache("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := profileCache.Save(&comdirect.AuthToken{AccessToken: "current-access"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := profileCache.MigrateLegacy(); err != nil {
+		t.Fatal(err)
+	}
+	token, err := profileCache.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "current-access" {
+		t.Errorf("current token was replaced by %q", token.AccessToken)
+	}
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+)
+
+var errLocked = errors.New("cache is locked by another process")
+
+// Store holds one token cache per key, e.g. per profile, in a directory.
+type Store struct {
+	dir           string
+	encryptionKey string
+}
+
+func NewStore(dir string, encryptionKey string) *Store {
+	return &Store{
+		dir:           dir,
+		encryptionKey: encryptionKey,
+	}
+}
+
+// Cache returns the cache for key, the directory of the store is created if it does not exist.
+// A storage path pointing to a legacy cache file is turned into the directory, see migrateLegacyFile.
+func (s *Store) Cache(key string) (*Cache, error) {
+	if err := s.migrateLegacyFile(); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return nil, err
+	}
+	return NewCache(s.dir, key, s.encryptionKey), nil
+}
+
+// Lock acquires an exclusive advisory lock on the cache.
+// If another process holds the lock, e.g. while authenticating, Lock waits until it is released.
+// The returned function releases the lock.
+func (c *Cache) Lock() (func() error, error) {
+	f, err := os.OpenFile(c.store.Path(c.key)+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	err = lockFile(f, false)
+	if errors.Is(err, errLocked) {
+		slog.Info("Waiting for another comdirect process to finish authentication")
+		err = lockFile(f, true)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return func() error {
+		defer f.Close()
+		return unlockFile(f)
+	}, nil
+}
@@ -0,0 +1,159 @@
+// Package completion provides the dynamic shell completion of the commands.
+// Candidates are read from the config and the cached index only, completion never requests the API and never triggers a TAN.
+// The index is filled by list commands like account balances, depot depots and depot positions.
+package completion
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/fbufler/comdirect/config"
+	"github.com/fbufler/comdirect/internal/cache"
+	"github.com/fbufler/comdirect/internal/flows"
+	"github.com/spf13/cobra"
+)
+
+// Func is the signature of cobra's ValidArgsFunction and flag completion functions.
+type Func func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// Flag registers the completion of a flag of the command, the flag must exist.
+func Flag(cmd *cobra.Command, name string, complete Func) {
+	if err := cmd.RegisterFlagCompletionFunc(name, complete); err != nil {
+		panic(err)
+	}
+}
+
+// Values completes a fixed set of values, e.g. of an enum flag.
+func Values(values ...string) Func {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// Accounts completes the account of the first argument with the cached account IDs and the aliases.
+func Accounts(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	cfg, index := load()
+	if index == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var candidates []string
+	for _, account := range index.Accounts {
+		candidates = append(candidates, describe(account.AccountID, account.AccountDisplayID, account.AccountType.Text, account.IBAN))
+	}
+	candidates = append(candidates, aliases(cfg, index, flows.CachedAccountID)...)
+	return candidates, cobra.ShellCompDirectiveNoFileComp
+}
+
+// Depots completes the depot of the first argument with the cached depot IDs and the aliases.
+func Depots(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	cfg, index := load()
+	if index == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var candidates []string
+	for _, depot := range index.Depots {
+		candidates = append(candidates, describe(depot.DepotID, depot.DepotDisplayID, depot.DepotType))
+	}
+	candidates = append(candidates, aliases(cfg, index, flows.CachedDepotID)...)
+	return candidates, cobra.ShellCompDirectiveNoFileComp
+}
+
+// DepotPositions completes the depot of the first and the position of the second argument.
+func DepotPositions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return Depots(cmd, args, toComplete)
+	case 1:
+		cfg, index := load()
+		if index == nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		depotID, ok := flows.CachedDepotID(cfg, index, args[0])
+		if !ok {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var candidates []string
+		for _, position := range index.Positions[depotID] {
+			candidates = append(candidates, describe(position.PositionID, position.WKN, position.Name))
+		}
+		return candidates, cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// WKNs completes the WKNs of all cached positions.
+func WKNs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return instruments(func(position cache.Position) string { return position.WKN })
+}
+
+// ISINs completes the ISINs of all cached positions, they are only known for positions requested with --include-instrument.
+func ISINs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return instruments(func(position cache.Position) string { return position.ISIN })
+}
+
+// Profiles completes the profile names of the config.
+func Profiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return cfg.ProfileNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+func instruments(identifier func(position cache.Position) string) ([]string, cobra.ShellCompDirective) {
+	_, index := load()
+	if index == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var candidates []string
+	seen := map[string]bool{}
+	for _, positions := range index.Positions {
+		for _, position := range positions {
+			if id := identifier(position); id != "" && !seen[id] {
+				seen[id] = true
+				candidates = append(candidates, describe(id, position.Name))
+			}
+		}
+	}
+	slices.Sort(candidates)
+	return candidates, cobra.ShellCompDirectiveNoFileComp
+}
+
+// load returns the config and the cached index of the active profile, or nil if the config can't be loaded.
+func load() (*config.Config, *cache.Index) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil
+	}
+	return cfg, flows.CachedIndex(cfg)
+}
+
+// aliases returns the aliases resolving to the kind of resolve, aliases resolving to neither an account nor a depot are always returned.
+func aliases(cfg *config.Config, index *cache.Index, resolve func(cfg *config.Config, index *cache.Index, identifier string) (string, bool)) []string {
+	var candidates []string
+	for alias, target := range cfg.Client.Aliases {
+		_, account := flows.CachedAccountID(cfg, index, target)
+		_, depot := flows.CachedDepotID(cfg, index, target)
+		if _, ok := resolve(cfg, index, target); ok || (!account && !depot) {
+			candidates = append(candidates, describe(alias, "alias for "+target))
+		}
+	}
+	slices.Sort(candidates)
+	return candidates
+}
+
+// describe returns a candidate with the non-empty details as description.
+func describe(value string, details ...string) string {
+	details = slices.DeleteFunc(details, func(detail string) bool { return detail == "" })
+	if len(details) == 0 {
+		return value
+	}
+	return fmt.Sprintf("%s\t%s", value, strings.Join(details, " "))
+}
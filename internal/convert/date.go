@@ -0,0 +1,169 @@
+package convert
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // Europe/Berlin must resolve on systems without a zoneinfo database
+)
+
+// DateExpressions describes the accepted date expressions for flag help texts.
+const DateExpressions = "e.g. 2006-01-02, 02.01.2006, today, yesterday, -30d, -2w, -3m, -1y, this-month, last-month, this-week, last-week, this-quarter, last-quarter, this-year, last-year, ytd, 2024, 2024-07, 2024-Q3, 2024-W05"
+
+var (
+	relativePattern = regexp.MustCompile(`^-(\d+)([dwmy])$`)
+	yearPattern     = regexp.MustCompile(`^(\d{4})$`)
+	monthPattern    = regexp.MustCompile(`^(\d{4})-(\d{2})$`)
+	quarterPattern  = regexp.MustCompile(`^(\d{4})-q([1-4])$`)
+	weekPattern     = regexp.MustCompile(`^(\d{4})-w(\d{2})$`)
+)
+
+// berlin is the time zone of comdirect, relative expressions are resolved in it.
+var berlin = mustLoadLocation("Europe/Berlin")
+
+func mustLoadLocation(name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return location
+}
+
+// DateRange resolves a date expression to the first and last day it covers, relative to now in Europe/Berlin time.
+// Dates of the formats of TimeStringToTime and single day expressions like yesterday or -30d return the same day twice.
+// The days are returned as midnight UTC, like the dates parsed by TimeStringToTime.
+func DateRange(expression string, now time.Time) (start time.Time, end time.Time, err error) {
+	expression = strings.ToLower(strings.TrimSpace(expression))
+	now = now.In(berlin)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch expression {
+	case "today":
+		return today, today, nil
+	case "yesterday":
+		day := today.AddDate(0, 0, -1)
+		return day, day, nil
+	case "this-week":
+		start := isoWeekStart(today)
+		return start, start.AddDate(0, 0, 6), nil
+	case "last-week":
+		start := isoWeekStart(today).AddDate(0, 0, -7)
+		return start, start.AddDate(0, 0, 6), nil
+	case "this-month":
+		return monthRange(today.Year(), today.Month())
+	case "last-month":
+		return monthRange(today.Year(), today.Month()-1)
+	case "this-quarter":
+		return quarterRange(today.Year(), quarter(today.Month()))
+	case "last-quarter":
+		return quarterRange(today.Year(), quarter(today.Month())-1)
+	case "this-year":
+		return yearRange(today.Year())
+	case "last-year":
+		return yearRange(today.Year() - 1)
+	case "ytd":
+		return time.Date(today.Year(), time.January, 1, 0, 0, 0, 0, time.UTC), today, nil
+	}
+
+	if match := relativePattern.FindStringSubmatch(expression); match != nil {
+		amount, _ := strconv.Atoi(match[1])
+		var day time.Time
+		switch match[2] {
+		case "d":
+			day = today.AddDate(0, 0, -amount)
+		case "w":
+			day = today.AddDate(0, 0, -7*amount)
+		case "m":
+			day = addMonths(today, -amount)
+		case "y":
+			day = addMonths(today, -12*amount)
+		}
+		return day, day, nil
+	}
+	if match := yearPattern.FindStringSubmatch(expression); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		return yearRange(year)
+	}
+	if match := monthPattern.FindStringSubmatch(expression); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		month, _ := strconv.Atoi(match[2])
+		if month < 1 || month > 12 {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid month in %q", expression)
+		}
+		return monthRange(year, time.Month(month))
+	}
+	if match := quarterPattern.FindStringSubmatch(expression); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		q, _ := strconv.Atoi(match[2])
+		return quarterRange(year, q)
+	}
+	if match := weekPattern.FindStringSubmatch(expression); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		week, _ := strconv.Atoi(match[2])
+		return weekRange(year, week)
+	}
+
+	day, err := TimeStringToTime(expression)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date %q, %s", expression, DateExpressions)
+	}
+	return day, day, nil
+}
+
+// StartDate returns the first day of a date expression, see DateRange.
+func StartDate(expression string, now time.Time) (time.Time, error) {
+	start, _, err := DateRange(expression, now)
+	return start, err
+}
+
+// EndDate returns the last day of a date expression, see DateRange.
+func EndDate(expression string, now time.Time) (time.Time, error) {
+	_, end, err := DateRange(expression, now)
+	return end, err
+}
+
+func yearRange(year int) (time.Time, time.Time, error) {
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(1, 0, -1), nil
+}
+
+// monthRange returns the first and last day of a month, months outside of 1 to 12 roll over into the adjacent years.
+func monthRange(year int, month time.Month) (time.Time, time.Time, error) {
+	start := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, -1), nil
+}
+
+// quarterRange returns the first and last day of a quarter, quarter 0 is the last quarter of the previous year.
+func quarterRange(year int, quarter int) (time.Time, time.Time, error) {
+	start := time.Date(year, time.Month(3*(quarter-1)+1), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 3, -1), nil
+}
+
+func quarter(month time.Month) int {
+	return (int(month)-1)/3 + 1
+}
+
+// weekRange returns the Monday and Sunday of an ISO 8601 week.
+func weekRange(year int, week int) (time.Time, time.Time, error) {
+	// the 4th of January is always in the first week, the 28th of December always in the last
+	_, weeks := time.Date(year, time.December, 28, 0, 0, 0, 0, time.UTC).ISOWeek()
+	if week < 1 || week > weeks {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid week %d, %d has %d ISO weeks", week, year, weeks)
+	}
+	start := isoWeekStart(time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)).AddDate(0, 0, 7*(week-1))
+	return start, start.AddDate(0, 0, 6), nil
+}
+
+// isoWeekStart returns the Monday of the week of day.
+func isoWeekStart(day time.Time) time.Time {
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// addMonths adds months to day, the day is clamped to the last day of shorter months, e.g. 31.03. - 1 month is 29.02.
+func addMonths(day time.Time, months int) time.Time {
+	first := time.Date(day.Year(), day.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(day.Day(), last)-1)
+}
@@ -0,0 +1,100 @@
+// Package exit maps the errors of the commands to exit codes and prints them as text or JSON on stderr.
+package exit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/fbufler/comdirect/config"
+	"github.com/fbufler/comdirect/internal/completion"
+	"github.com/fbufler/comdirect/internal/flows"
+	"github.com/fbufler/comdirect/pkg/comdirect"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of the CLI, every failure not listed exits with CodeError.
+const (
+	CodeOK          = 0
+	CodeError       = 1
+	CodeConfig      = 2
+	CodeAuth        = 3
+	CodeAPI         = 4
+	CodeRateLimited = 5
+	CodeNotFound    = 6
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Error holds the details of a failed command, it is written with --error-format json.
+type Error struct {
+	Message  string `json:"error"`
+	Kind     string `json:"kind"`
+	ExitCode int    `json:"exitCode"`
+	// StatusCode, Method, Path and RequestID are set if a request failed, see comdirect.RequestError
+	StatusCode int    `json:"statusCode,omitempty"`
+	Method     string `json:"method,omitempty"`
+	Path       string `json:"path,omitempty"`
+	RequestID  string `json:"requestId,omitempty"`
+}
+
+// AddFlags adds the --error-format flag to the command and its subcommands.
+func AddFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().String("error-format", FormatText, "Error format (text, json)")
+	completion.Flag(cmd, "error-format", completion.Values(FormatText, FormatJSON))
+}
+
+// Details classifies err. Config errors and rate limiting take precedence, a request failing during authentication is an authentication error.
+func Details(err error) Error {
+	details := Error{Message: err.Error(), Kind: "error", ExitCode: CodeError}
+
+	var requestErr *comdirect.RequestError
+	if errors.As(err, &requestErr) {
+		details.StatusCode = requestErr.StatusCode
+		details.Method = requestErr.Method
+		details.Path = requestErr.Path
+		details.RequestID = requestErr.RequestID
+	}
+
+	switch {
+	case errors.Is(err, config.ErrConfig):
+		details.Kind, details.ExitCode = "config", CodeConfig
+	case requestErr != nil && requestErr.StatusCode == http.StatusTooManyRequests:
+		details.Kind, details.ExitCode = "rate-limited", CodeRateLimited
+	case errors.Is(err, flows.ErrAuthentication), errors.Is(err, flows.ErrTANRequired), errors.Is(err, flows.ErrNoSession):
+		details.Kind, details.ExitCode = "auth", CodeAuth
+	case requestErr == nil:
+	case requestErr.StatusCode == http.StatusUnauthorized, requestErr.StatusCode == http.StatusForbidden:
+		details.Kind, details.ExitCode = "auth", CodeAuth
+	case requestErr.StatusCode == http.StatusNotFound:
+		details.Kind, details.ExitCode = "not-found", CodeNotFound
+	default:
+		details.Kind, details.ExitCode = "api", CodeAPI
+	}
+	return details
+}
+
+// Handle prints err on stderr of the command in the format of --error-format and returns the exit code.
+// A nil error returns CodeOK without printing anything.
+func Handle(cmd *cobra.Command, err error) int {
+	if err == nil {
+		return CodeOK
+	}
+	details := Details(err)
+	format, _ := cmd.PersistentFlags().GetString("error-format")
+	if format != FormatJSON {
+		cmd.PrintErrln(err)
+		return details.ExitCode
+	}
+	data, marshalErr := json.Marshal(details)
+	if marshalErr != nil {
+		cmd.PrintErrln(err)
+		return details.ExitCode
+	}
+	fmt.Fprintln(cmd.ErrOrStderr(), string(data))
+	return details.ExitCode
+}
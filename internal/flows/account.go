@@ -1,95 +1,51 @@
 package flows
 
 import (
-	"encoding/json"
+	"iter"
 
-	"github.com/fbufler/comdirect/config"
+	"github.com/fbufler/comdirect/internal/listing"
 	"github.com/fbufler/comdirect/pkg/comdirect"
 )
 
-func AccountBalances(cfg *config.Config, excludeAccount bool) (string, error) {
+// AccountBalances returns the pages of balances of all accounts selected by the listing options.
+// Pages are requested while iterating, see output.PrintPages.
+func AccountBalances(client comdirect.Banking, token *comdirect.AuthToken, excludeAccount bool, list listing.Options) iter.Seq2[*comdirect.AccountBalances, error] {
 	options := &comdirect.AccountBalancesOptions{
 		ExludeAccount: excludeAccount,
+		PagingCount:   list.PageSize,
 	}
-	client, token, err := Bootstrap(cfg)
-	if err != nil {
-		return "", err
-	}
-
-	accounts, err := client.AccountBalances(token, options)
-	if err != nil {
-		return "", err
-	}
-
-	data, err := json.Marshal(accounts)
-	if err != nil {
-		return "", err
-	}
-
-	return string(data), nil
+	pages := client.AccountBalancePages(token, options)
+	return listPages(pages, list, func(page *comdirect.AccountBalances) *[]comdirect.AccountBalance {
+		return &page.Values
+	}, nil)
 }
 
-func AccountBalance(cfg *config.Config, accountID string) (string, error) {
-	client, token, err := Bootstrap(cfg)
-	if err != nil {
-		return "", err
-	}
-
+func AccountBalance(client comdirect.Banking, token *comdirect.AuthToken, accountID string) (*comdirect.AccountBalance, error) {
 	account, err := client.AccountBalance(token, accountID)
 	if err != nil {
-		return "", err
-	}
-
-	data, err := json.Marshal(account)
-	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return string(data), nil
+	return account, nil
 }
 
-func AccountTransactions(cfg *config.Config, accountID string, transactionState comdirect.TransactionState, includeAccount bool) (string, error) {
+// AccountTransactions returns the pages of transactions of an account selected by the listing options.
+// Only booked transactions can be paged, so the transaction state is ignored with Count, All or a date bound.
+func AccountTransactions(client comdirect.Banking, token *comdirect.AuthToken, accountID string, transactionState comdirect.TransactionState, includeAccount bool, list listing.Options) iter.Seq2[*comdirect.AccountTransactions, error] {
+	if list.Paginated() || list.Bounded() {
+		transactionState = comdirect.TransactionStateBooked
+	}
 	options := &comdirect.AccountTransactionOptions{
 		IncludeAccount:   includeAccount,
+		PagingCount:      list.PageSize,
 		TransactionState: transactionState,
-	}
-	client, token, err := Bootstrap(cfg)
-	if err != nil {
-		return "", err
-	}
-
-	transactions, err := client.AccountTransactions(token, accountID, options)
-	if err != nil {
-		return "", err
-	}
-
-	data, err := json.Marshal(transactions)
-	if err != nil {
-		return "", err
-	}
-
-	return string(data), nil
-}
-
-func PaginatedAccountTransactions(cfg *config.Config, accountID string, amount int, includeAccount bool) (string, error) {
-	options := &comdirect.AccountTransactionOptions{
-		IncludeAccount: includeAccount,
-		PagingFirst:    amount,
-	}
-	client, token, err := Bootstrap(cfg)
-	if err != nil {
-		return "", err
-	}
-
-	transactions, err := client.PaginatedAccountTransactions(token, accountID, amount, options)
-	if err != nil {
-		return "", err
-	}
-
-	data, err := json.Marshal(transactions)
-	if err != nil {
-		return "", err
-	}
-
-	return string(data), nil
+		MinBookingDate:   list.Since,
+		MaxBookingDate:   list.Until,
+	}
+	pages := client.AccountTransactionPages(token, accountID, options)
+	return listPages(pages, list, func(page *comdirect.AccountTransactions) *[]comdirect.AccountTransaction {
+		return &page.Values
+	}, func(transaction comdirect.AccountTransaction) string {
+		return transaction.BookingDate
+	})
 }
@@ -2,6 +2,7 @@ package flows
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -11,57 +12,144 @@ import (
 	"github.com/fbufler/comdirect/pkg/comdirect"
 )
 
-func Bootstrap(cfg *config.Config) (*comdirect.Client, *comdirect.AuthToken, error) {
+// Bootstrap creates a client for the config and authenticates it, see Authenticate.
+// Secrets configured as file or command are resolved in memory.
+// If no pin is configured, it is prompted for once a new token has to be requested,
+// the returned client is then the one created with the entered pin.
+func Bootstrap(cfg *config.Config, opts ...comdirect.Option) (*comdirect.Client, *comdirect.AuthToken, error) {
+	clientConfig, err := cfg.Client.ResolveSecrets()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client := newClient(clientConfig, opts...)
+	if clientConfig.Pin != "" {
+		token, err := Authenticate(cfg, client)
+		return client, token, err
+	}
+
+	prompter := &pinPrompter{
+		Authenticator: client,
+		newClient: func(pin string) *comdirect.Client {
+			clientConfig.Pin = pin
+			return newClient(clientConfig, opts...)
+		},
+	}
+	token, err := Authenticate(cfg, prompter)
+	// the client created with the pin authenticated the token, later requests use it
+	if prompter.client != nil {
+		client = prompter.client
+	}
+	return client, token, err
+}
+
+func newClient(clientConfig config.ClientConfig, opts ...comdirect.Option) *comdirect.Client {
 	config := comdirect.Config{
-		APIURL:         cfg.Client.APIURL,
-		TokenURL:       cfg.Client.TokenURL,
-		RevokeTokenURL: cfg.Client.RevokeTokenURL,
-		ClientID:       cfg.Client.ClientID,
-		ClientSecret:   cfg.Client.ClientSecret,
-		Zugangsnummer:  cfg.Client.Zugangsnummer,
-		Pin:            cfg.Client.Pin,
+		APIURL:         clientConfig.APIURL,
+		TokenURL:       clientConfig.TokenURL,
+		RevokeTokenURL: clientConfig.RevokeTokenURL,
+		ClientID:       clientConfig.ClientID,
+		ClientSecret:   clientConfig.ClientSecret,
+		Zugangsnummer:  clientConfig.Zugangsnummer,
+		Pin:            clientConfig.Pin,
 	}
+	return comdirect.NewClient(config, opts...)
+}
 
-	client := comdirect.NewClient(config)
-	cache := cache.NewCache(cfg.Cli.StoragePath, cfg.Cli.EncryptionKey)
+// Authenticate returns a token from the cache if it is not expired.
+// An expired cached token is refreshed, only if refreshing fails the authentication flow is run.
+// New and refreshed tokens are stored in the cache.
+// The cache is locked during authentication, so concurrent runs wait for each other instead of authenticating twice.
+func Authenticate(cfg *config.Config, authenticator comdirect.Authenticator) (*comdirect.AuthToken, error) {
+	tokenCache, err := openCache(cfg)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("unable to open token cache: %s", err))
+	}
 
-	token, err := loadCache(cfg)
-	if token != nil && !token.IsExpired() {
-		return client, token, nil
+	if tokenCache != nil {
+		unlock, err := tokenCache.Lock()
+		if err != nil {
+			slog.Warn(fmt.Sprintf("unable to lock token cache: %s", err))
+		} else {
+			defer unlock()
+		}
 	}
 
+	token, err := loadCache(tokenCache)
 	if err != nil {
 		slog.Warn(fmt.Sprintf("unable to load token from cache: %s", err))
-		slog.Info("proceeding with authentication flow")
 	}
 
-	token, err = client.Authenticate(twoFaHandler)
-	if err != nil {
-		return client, token, err
+	if token != nil {
+		if !token.IsExpired() {
+			return token, nil
+		}
+
+		slog.Info("cached token expired, refreshing")
+		refreshedToken, err := authenticator.RefreshToken(token)
+		if err == nil {
+			saveCache(tokenCache, refreshedToken)
+			return refreshedToken, nil
+		}
+		slog.Warn(fmt.Sprintf("unable to refresh token: %s", err))
 	}
 
-	if cfg.Cli.EnableCache {
-		err = cache.Save(token)
-		if err != nil {
-			slog.Warn("unable to store token in cache")
+	slog.Info("proceeding with authentication flow")
+	token, err = authenticator.Authenticate(twoFaHandler)
+	if err != nil {
+		// config errors like a missing pin are kept, everything else failed the authentication
+		if errors.Is(err, config.ErrConfig) {
+			return token, err
 		}
+		return token, fmt.Errorf("%w: %w", ErrAuthentication, err)
 	}
 
-	return client, token, err
+	saveCache(tokenCache, token)
+	return token, nil
 }
 
-func loadCache(cfg *config.Config) (*comdirect.AuthToken, error) {
-	if cfg.Cli.EnableCache {
-		c := cache.NewCache(cfg.Cli.StoragePath, cfg.Cli.EncryptionKey)
-		token, err := c.Load()
-		if err != nil {
-			return nil, err
-		}
-		if token != nil {
-			return token, nil
+// openCache returns the token cache of the active profile, or nil if the cache is disabled.
+func openCache(cfg *config.Config) (*cache.Cache, error) {
+	return openProfileCache(cfg, cfg.Profile)
+}
+
+func openProfileCache(cfg *config.Config, profile string) (*cache.Cache, error) {
+	if !cfg.Cli.EnableCache {
+		return nil, nil
+	}
+	store := cache.NewStore(cfg.Cli.StoragePath, cfg.Cli.EncryptionKey)
+	profileCache, err := store.Cache(profile)
+	if err != nil {
+		return nil, err
+	}
+	// older versions kept a single cache, which belonged to the login of the default profile
+	if profile == config.DefaultProfile {
+		if err := profileCache.MigrateLegacy(); err != nil {
+			slog.Warn(fmt.Sprintf("unable to migrate the legacy token cache: %s", err))
 		}
 	}
-	return nil, nil
+	return profileCache, nil
+}
+
+func saveCache(tokenCache *cache.Cache, token *comdirect.AuthToken) {
+	if tokenCache == nil {
+		return
+	}
+	err := tokenCache.Save(token)
+	if err != nil {
+		slog.Warn("unable to store token in cache")
+	}
+}
+
+func loadCache(tokenCache *cache.Cache) (*comdirect.AuthToken, error) {
+	if tokenCache == nil {
+		return nil, nil
+	}
+	token, err := tokenCache.Load()
+	if errors.Is(err, comdirect.ErrTokenNotFound) {
+		return nil, nil
+	}
+	return token, err
 }
 
 func twoFaHandler(tanHeader comdirect.TANHeader) error {
@@ -70,7 +158,10 @@ func twoFaHandler(tanHeader comdirect.TANHeader) error {
 
 	slog.Info("Press enter to continue")
 	input := bufio.NewScanner(os.Stdin)
-	input.Scan()
+	if !input.Scan() {
+		// nobody can confirm the TAN, e.g. in a cron job with stdin closed
+		return ErrTANRequired
+	}
 
 	slog.Info("Continuing")
 	return nil
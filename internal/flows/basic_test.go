@@ -0,0 +1,152 @@
+package flows
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fbufler/comdirect/config"
+	"github.com/fbufler/comdirect/pkg/comdirect"
+	"github.com/fbufler/comdirect/pkg/comdirect/comdirectmock"
+)
+
+// newCacheConfig returns a config with the cache enabled in a temporary directory.
+// The profile is not the default profile, so no legacy cache is migrated.
+func newCacheConfig(t *testing.T) *config.Config {
+	t.Helper()
+	return &config.Config{
+		Profile: "test",
+		Cli: config.CliConfig{
+			EnableCache:   true,
+			EncryptionKey: "0123456789abcdef",
+			StoragePath:   t.TempDir(),
+		},
+	}
+}
+
+// cacheToken stores the token in the cache of the config.
+func cacheToken(t *testing.T, cfg *config.Config, token *comdirect.AuthToken) {
+	t.Helper()
+	tokenCache, err := openCache(cfg)
+	if err != nil {
+		t.Fatalf("open cache: %v", err)
+	}
+	if err := tokenCache.Save(token); err != nil {
+		t.Fatalf("save token: %v", err)
+	}
+}
+
+func cachedToken(t *testing.T, cfg *config.Config) *comdirect.AuthToken {
+	t.Helper()
+	tokenCache, err := openCache(cfg)
+	if err != nil {
+		t.Fatalf("open cache: %v", err)
+	}
+	token, err := loadCache(tokenCache)
+	if err != nil {
+		t.Fatalf("load token: %v", err)
+	}
+	return token
+}
+
+func newToken(accessToken string, createdAgo time.Duration) *comdirect.AuthToken {
+	return &comdirect.AuthToken{
+		AccessToken:  accessToken,
+		RefreshToken: "refresh-" + accessToken,
+		ExpiresIn:    600,
+		CreationTime: time.Now().Add(-createdAgo).Truncate(time.Second),
+		SessionGUID:  "session",
+	}
+}
+
+// authenticator returns a mock counting the logins and refreshes, which return the given tokens and errors.
+func authenticator(logins, refreshes *int, login *comdirect.AuthToken, loginErr error, refreshed *comdirect.AuthToken, refreshErr error) *comdirectmock.Client {
+	return &comdirectmock.Client{
+		AuthenticateFunc: func(twoFaHandler func(tanHeader comdirect.TANHeader) error) (*comdirect.AuthToken, error) {
+			*logins++
+			return login, loginErr
+		},
+		RefreshTokenFunc: func(token *comdirect.AuthToken) (*comdirect.AuthToken, error) {
+			*refreshes++
+			return refreshed, refreshErr
+		},
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	tests := []struct {
+		name          string
+		cached        *comdirect.AuthToken
+		refreshErr    error
+		wantToken     string
+		wantLogins    int
+		wantRefreshes int
+	}{
+		{"without cached token", nil, nil, "login", 1, 0},
+		{"live cached token", newToken("cached", time.Minute), nil, "cached", 0, 0},
+		{"expired cached token", newToken("cached", time.Hour), nil, "refreshed", 0, 1},
+		{"refresh fails", newToken("cached", time.Hour), errors.New("refresh token expired"), "login", 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newCacheConfig(t)
+			if tt.cached != nil {
+				cacheToken(t, cfg, tt.cached)
+			}
+			var logins, refreshes int
+			client := authenticator(&logins, &refreshes, newToken("login", 0), nil, newToken("refreshed", 0), tt.refreshErr)
+
+			token, err := Authenticate(cfg, client)
+			if err != nil {
+				t.Fatalf("authenticate: %v", err)
+			}
+			if token.AccessToken != tt.wantToken {
+				t.Errorf("got token %q, want %q", token.AccessToken, tt.wantToken)
+			}
+			if logins != tt.wantLogins || refreshes != tt.wantRefreshes {
+				t.Errorf("got %d logins and %d refreshes, want %d and %d", logins, refreshes, tt.wantLogins, tt.wantRefreshes)
+			}
+			if cached := cachedToken(t, cfg); cached == nil || cached.AccessToken != tt.wantToken {
+				t.Errorf("got cached token %+v, want %q", cached, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestAuthenticateWithoutCache(t *testing.T) {
+	var logins, refreshes int
+	client := authenticator(&logins, &refreshes, newToken("login", 0), nil, nil, nil)
+
+	token, err := Authenticate(&config.Config{}, client)
+	if err != nil {
+		t.Fatalf("authenticate: %v", err)
+	}
+	if token.AccessToken != "login" || logins != 1 {
+		t.Errorf("got token %q after %d logins, want a single login", token.AccessToken, logins)
+	}
+}
+
+func TestAuthenticateErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		loginErr error
+		want     error
+	}{
+		{"failed login", errors.New("wrong pin"), ErrAuthentication},
+		{"config error", config.Errorf("no pin configured"), config.ErrConfig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logins, refreshes int
+			client := authenticator(&logins, &refreshes, nil, tt.loginErr, nil, nil)
+
+			_, err := Authenticate(newCacheConfig(t), client)
+			if !errors.Is(err, tt.want) || !errors.Is(err, tt.loginErr) {
+				t.Errorf("got error %v, want it to wrap %v and %v", err, tt.want, tt.loginErr)
+			}
+			if tt.want == config.ErrConfig && errors.Is(err, ErrAuthentication) {
+				t.Errorf("config error %v is reported as authentication error", err)
+			}
+		})
+	}
+}
@@ -1,170 +1,67 @@
 package flows
 
 import (
-	"encoding/json"
+	"iter"
 	"time"
 
-	"github.com/fbufler/comdirect/config"
+	"github.com/fbufler/comdirect/internal/listing"
 	"github.com/fbufler/comdirect/pkg/comdirect"
 )
 
-func Depots(cfg *config.Config) (string, error) {
-	client, token, err := Bootstrap(cfg)
-	if err != nil {
-		return "", err
-	}
-
-	depots, err := client.Depots(token, nil)
-	if err != nil {
-		return "", err
-	}
-
-	data, err := json.Marshal(depots)
-	if err != nil {
-		return "", err
-	}
-
-	return string(data), nil
-}
-
-func PaginatedDepots(cfg *config.Config, amount int) (string, error) {
-	client, token, err := Bootstrap(cfg)
-	if err != nil {
-		return "", err
-	}
-
-	depots, err := client.PaginatedDepots(token, amount)
-	if err != nil {
-		return "", err
+// Depots returns the pages of depots selected by the listing options.
+func Depots(client comdirect.Brokerage, token *comdirect.AuthToken, list listing.Options) iter.Seq2[*comdirect.Depots, error] {
+	options := &comdirect.DepotsOptions{
+		PagingCount: list.PageSize,
 	}
-
-	data, err := json.Marshal(depots)
-	if err != nil {
-		return "", err
-	}
-
-	return string(data), nil
+	pages := client.DepotPages(token, options)
+	return listPages(pages, list, func(page *comdirect.Depots) *[]comdirect.Depot {
+		return &page.Values
+	}, nil)
 }
 
-func DepotPosition(cfg *config.Config, depotID, positionID string, includeInstrument bool) (string, error) {
+func DepotPosition(client comdirect.Brokerage, token *comdirect.AuthToken, depotID, positionID string, includeInstrument bool) (*comdirect.DepotPosition, error) {
 	options := &comdirect.DepotPositionOptions{
 		IncludeInstrument: includeInstrument,
 	}
-	client, token, err := Bootstrap(cfg)
-	if err != nil {
-		return "", err
-	}
-
 	depot, err := client.DepotPosition(token, depotID, positionID, options)
 	if err != nil {
-		return "", err
-	}
-
-	data, err := json.Marshal(depot)
-	if err != nil {
-		return "", err
-	}
-
-	return string(data), nil
-}
-
-func DepotPositions(cfg *config.Config, depotID string, includeInstrument, excludeDepot bool) (string, error) {
-	options := &comdirect.DepotPosistionsOptions{
-		IncludeInstrument: includeInstrument,
-		ExcludeDepot:      excludeDepot,
-	}
-
-	client, token, err := Bootstrap(cfg)
-	if err != nil {
-		return "", err
-	}
-
-	depot, err := client.DepotPositions(token, depotID, options)
-	if err != nil {
-		return "", err
-	}
-
-	data, err := json.Marshal(depot)
-	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return string(data), nil
+	return depot, nil
 }
 
-func PaginatedDepotPositions(cfg *config.Config, depotID string, amount int, includeInstrument, excludeDepot bool) (string, error) {
-	client, token, err := Bootstrap(cfg)
-	if err != nil {
-		return "", err
-	}
-
+// DepotPositions returns the pages of positions of a depot selected by the listing options.
+func DepotPositions(client comdirect.Brokerage, token *comdirect.AuthToken, depotID string, includeInstrument, excludeDepot bool, list listing.Options) iter.Seq2[*comdirect.DepotPositions, error] {
 	options := &comdirect.DepotPosistionsOptions{
 		IncludeInstrument: includeInstrument,
 		ExcludeDepot:      excludeDepot,
+		PagingCount:       list.PageSize,
 	}
-
-	depotPositions, err := client.PaginatedDepotPositions(token, depotID, amount, options)
-	if err != nil {
-		return "", err
-	}
-
-	data, err := json.Marshal(depotPositions)
-	if err != nil {
-		return "", err
-	}
-
-	return string(data), nil
+	pages := client.DepotPositionPages(token, depotID, options)
+	return listPages(pages, list, func(page *comdirect.DepotPositions) *[]comdirect.DepotPosition {
+		return &page.Values
+	}, nil)
 }
 
-func DepotTransactions(cfg *config.Config, depotID string, wkn, isin, instrumentId string, bookingStatus comdirect.BookingStatus, maxBookingDate time.Time) (string, error) {
-	options := &comdirect.DepotTransactionOptions{
-		WKN:            wkn,
-		ISIN:           isin,
-		InstrumentId:   instrumentId,
-		BookingStatus:  bookingStatus,
-		MaxBookingDate: maxBookingDate,
-	}
-	client, token, err := Bootstrap(cfg)
-	if err != nil {
-		return "", err
+// DepotTransactions returns the pages of transactions of a depot selected by the listing options.
+// The API only filters by the latest booking date, Since is applied to the values of each page.
+func DepotTransactions(client comdirect.Brokerage, token *comdirect.AuthToken, depotID string, wkn, isin, instrumentId string, bookingStatus comdirect.BookingStatus, maxBookingDate time.Time, list listing.Options) iter.Seq2[*comdirect.DepotTransactions, error] {
+	if maxBookingDate.IsZero() || (!list.Until.IsZero() && list.Until.Before(maxBookingDate)) {
+		maxBookingDate = list.Until
 	}
-
-	depotTransactions, err := client.DepotTransactions(token, depotID, options)
-	if err != nil {
-		return "", err
-	}
-
-	data, err := json.Marshal(depotTransactions)
-	if err != nil {
-		return "", err
-	}
-
-	return string(data), nil
-}
-
-func PaginatedDepotTransactions(cfg *config.Config, depotID string, amount int, wkn, isin, instrumentId string, bookingStatus comdirect.BookingStatus, maxBookingDate time.Time) (string, error) {
 	options := &comdirect.DepotTransactionOptions{
 		WKN:            wkn,
 		ISIN:           isin,
 		InstrumentId:   instrumentId,
 		BookingStatus:  bookingStatus,
 		MaxBookingDate: maxBookingDate,
-		PagingFirst:    amount,
-	}
-	client, token, err := Bootstrap(cfg)
-	if err != nil {
-		return "", err
-	}
-
-	depotTransactions, err := client.PaginatedDepotTransactions(token, depotID, amount, options)
-	if err != nil {
-		return "", err
-	}
-
-	data, err := json.Marshal(depotTransactions)
-	if err != nil {
-		return "", err
-	}
-
-	return string(data), nil
+		PagingCount:    list.PageSize,
+	}
+	pages := client.DepotTransactionPages(token, depotID, options)
+	return listPages(pages, list, func(page *comdirect.DepotTransactions) *[]comdirect.DepotTransaction {
+		return &page.Values
+	}, func(transaction comdirect.DepotTransaction) string {
+		return transaction.BookingDate
+	})
 }
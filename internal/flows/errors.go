@@ -0,0 +1,12 @@
+package flows
+
+import "errors"
+
+var (
+	// ErrAuthentication is matched by every error of the authentication flow, e.g. a wrong pin or a declined TAN.
+	ErrAuthentication = errors.New("authentication failed")
+	// ErrTANRequired is returned if a TAN has to be confirmed but stdin is closed, e.g. in a cron job.
+	ErrTANRequired = errors.New("TAN confirmation required, run 'comdirect login' in a terminal first")
+	// ErrNoSession is returned if no session is cached for the active profile.
+	ErrNoSession = errors.New("no session, run 'comdirect login' first")
+)
@@ -0,0 +1,130 @@
+package flows
+
+import (
+	"iter"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fbufler/comdirect/config"
+	"github.com/fbufler/comdirect/internal/cache"
+	"github.com/fbufler/comdirect/pkg/comdirect"
+)
+
+// CachedIndex returns the cached index of the active profile without requesting anything, e.g. for completion.
+// Without cache an empty index is returned.
+func CachedIndex(cfg *config.Config) *cache.Index {
+	_, index := loadIndex(cfg)
+	return index
+}
+
+// CachedAccountID resolves an identifier like ResolveAccountID, but only with the cached index.
+// It reports false if no cached account uniquely matches.
+func CachedAccountID(cfg *config.Config, index *cache.Index, identifier string) (string, bool) {
+	return cachedID(cfg, "account", identifier, accountCandidates(index.Accounts))
+}
+
+// CachedDepotID resolves an identifier like ResolveDepotID, but only with the cached index.
+// It reports false if no cached depot uniquely matches.
+func CachedDepotID(cfg *config.Config, index *cache.Index, identifier string) (string, bool) {
+	return cachedID(cfg, "depot", identifier, depotCandidates(index.Depots))
+}
+
+func cachedID(cfg *config.Config, kind string, identifier string, candidates []candidate) (string, bool) {
+	if target, ok := cfg.Client.Aliases[strings.ToLower(identifier)]; ok {
+		identifier = target
+	}
+	id, ok, err := match(kind, identifier, candidates)
+	return id, ok && err == nil
+}
+
+// IndexAccounts passes the pages through and records their accounts in the cached index.
+func IndexAccounts(cfg *config.Config, pages iter.Seq2[*comdirect.AccountBalances, error]) iter.Seq2[*comdirect.AccountBalances, error] {
+	return indexPages(cfg, pages, func(index *cache.Index, page *comdirect.AccountBalances) {
+		for _, balance := range page.Values {
+			// the account is missing with --exclude-account
+			if balance.Account.AccountID != "" {
+				index.Accounts = upsert(index.Accounts, balance.Account, func(account comdirect.Account) string {
+					return account.AccountID
+				})
+			}
+		}
+	})
+}
+
+// IndexDepots passes the pages through and records their depots in the cached index.
+func IndexDepots(cfg *config.Config, pages iter.Seq2[*comdirect.Depots, error]) iter.Seq2[*comdirect.Depots, error] {
+	return indexPages(cfg, pages, func(index *cache.Index, page *comdirect.Depots) {
+		for _, depot := range page.Values {
+			index.Depots = upsert(index.Depots, depot, func(depot comdirect.Depot) string {
+				return depot.DepotID
+			})
+		}
+	})
+}
+
+// IndexPositions passes the pages through and records the positions of the depot in the cached index.
+// ISIN and name of a position are kept if the instrument was not requested.
+func IndexPositions(cfg *config.Config, depotID string, pages iter.Seq2[*comdirect.DepotPositions, error]) iter.Seq2[*comdirect.DepotPositions, error] {
+	return indexPages(cfg, pages, func(index *cache.Index, page *comdirect.DepotPositions) {
+		if index.Positions == nil {
+			index.Positions = map[string][]cache.Position{}
+		}
+		positions := index.Positions[depotID]
+		for _, value := range page.Values {
+			position := cache.Position{PositionID: value.PositionID, WKN: value.WKN}
+			if i := slices.IndexFunc(positions, func(p cache.Position) bool { return p.PositionID == value.PositionID }); i >= 0 {
+				position.ISIN, position.Name = positions[i].ISIN, positions[i].Name
+			}
+			if value.Instrument != nil {
+				position.ISIN, position.Name = value.Instrument.ISIN, value.Instrument.Name
+			}
+			positions = upsert(positions, position, func(position cache.Position) string {
+				return position.PositionID
+			})
+		}
+		index.Positions[depotID] = positions
+	})
+}
+
+// indexPages records every page with record and stores the index once the pages are consumed.
+// Without cache the pages are passed through unchanged.
+func indexPages[P any](cfg *config.Config, pages iter.Seq2[P, error], record func(index *cache.Index, page P)) iter.Seq2[P, error] {
+	return func(yield func(P, error) bool) {
+		indexCache, index := loadIndex(cfg)
+		if indexCache == nil {
+			for page, err := range pages {
+				if !yield(page, err) {
+					return
+				}
+			}
+			return
+		}
+
+		recorded := false
+		defer func() {
+			if recorded {
+				index.UpdatedAt = time.Now()
+				saveIndex(indexCache, index)
+			}
+		}()
+		for page, err := range pages {
+			if err == nil {
+				record(index, page)
+				recorded = true
+			}
+			if !yield(page, err) {
+				return
+			}
+		}
+	}
+}
+
+// upsert replaces the value with the same key or appends it.
+func upsert[V any](values []V, value V, key func(V) string) []V {
+	if i := slices.IndexFunc(values, func(v V) bool { return key(v) == key(value) }); i >= 0 {
+		values[i] = value
+		return values
+	}
+	return append(values, value)
+}
@@ -0,0 +1,47 @@
+package flows
+
+import (
+	"iter"
+	"slices"
+
+	"github.com/fbufler/comdirect/internal/listing"
+)
+
+// listPages applies the listing options to the pages of a list, values returns the values of a page.
+// With bookingDate set, values outside of Since and Until are dropped from each page.
+// With Count the pages end after Count values, without Count and All after the first page.
+// Without Count and All but with a date bound, pages are requested until a value reaches the bound, see listing.Options.ReachedBound.
+func listPages[P any, V any](pages iter.Seq2[P, error], options listing.Options, values func(page P) *[]V, bookingDate func(value V) string) iter.Seq2[P, error] {
+	return func(yield func(P, error) bool) {
+		remaining := options.Count
+		bounded := bookingDate != nil && options.Bounded()
+		for page, err := range pages {
+			if err != nil {
+				yield(page, err)
+				return
+			}
+			list := values(page)
+			reached := !bounded || slices.ContainsFunc(*list, func(value V) bool {
+				return options.ReachedBound(bookingDate(value))
+			})
+			if bookingDate != nil {
+				*list = slices.DeleteFunc(*list, func(value V) bool {
+					return !options.Includes(bookingDate(value))
+				})
+			}
+			if options.Count > 0 {
+				*list = (*list)[:min(len(*list), remaining)]
+				remaining -= len(*list)
+			}
+			if !yield(page, nil) {
+				return
+			}
+			if !options.Paginated() && reached {
+				return
+			}
+			if options.Count > 0 && remaining == 0 {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package flows
+
+import (
+	"iter"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/fbufler/comdirect/internal/listing"
+)
+
+type testPage struct {
+	values []string
+}
+
+// testPages returns pages of booking dates ordered newest first and counts the requested pages.
+func testPages(requested *int, pages ...[]string) iter.Seq2[*testPage, error] {
+	return func(yield func(*testPage, error) bool) {
+		for _, values := range pages {
+			*requested++
+			if !yield(&testPage{values: slices.Clone(values)}, nil) {
+				return
+			}
+		}
+	}
+}
+
+func date(value string) time.Time {
+	parsed, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+func TestListPages(t *testing.T) {
+	pages := [][]string{
+		{"2024-03-20", "2024-03-10"},
+		{"2024-02-25", "2024-02-10"},
+		{"2024-01-20", "2024-01-05"},
+	}
+	tests := []struct {
+		name          string
+		options       listing.Options
+		wantValues    []string
+		wantRequested int
+	}{
+		{"first page", listing.Options{}, []string{"2024-03-20", "2024-03-10"}, 1},
+		{"since within first page", listing.Options{Since: date("2024-03-15")}, []string{"2024-03-20"}, 1},
+		{"since on later page", listing.Options{Since: date("2024-02-01")}, []string{"2024-03-20", "2024-03-10", "2024-02-25", "2024-02-10"}, 3},
+		{"since before all", listing.Options{Since: date("2023-12-01")}, slices.Concat(pages...), 3},
+		{"until on later page", listing.Options{Until: date("2024-02-15")}, []string{"2024-02-10"}, 2},
+		{"since and until", listing.Options{Since: date("2024-02-01"), Until: date("2024-02-28")}, []string{"2024-02-25", "2024-02-10"}, 3},
+		{"count", listing.Options{Count: 3}, []string{"2024-03-20", "2024-03-10", "2024-02-25"}, 2},
+		{"count with since", listing.Options{Count: 1, Since: date("2024-01-01")}, []string{"2024-03-20"}, 1},
+		{"all with until", listing.Options{All: true, Until: date("2024-03-01")}, []string{"2024-02-25", "2024-02-10", "2024-01-20", "2024-01-05"}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requested int
+			var got []string
+			listed := listPages(testPages(&requested, pages...), tt.options, func(page *testPage) *[]string {
+				return &page.values
+			}, func(value string) string {
+				return value
+			})
+			for page, err := range listed {
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, page.values...)
+			}
+			if !slices.Equal(got, tt.wantValues) {
+				t.Errorf("got values %v, want %v", got, tt.wantValues)
+			}
+			if requested != tt.wantRequested {
+				t.Errorf("requested %d pages, want %d", requested, tt.wantRequested)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package flows
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/fbufler/comdirect/config"
+	"github.com/fbufler/comdirect/pkg/comdirect"
+	"golang.org/x/term"
+)
+
+// pinPrompter asks for the pin before authenticating, refreshing and revoking tokens don't need it.
+type pinPrompter struct {
+	comdirect.Authenticator
+	newClient func(pin string) *comdirect.Client
+	// client is the client created with the entered pin, it knows the token it authenticated
+	client *comdirect.Client
+}
+
+func (p *pinPrompter) Authenticate(twoFaHandler func(tanHeader comdirect.TANHeader) error) (*comdirect.AuthToken, error) {
+	pin, err := promptPin()
+	if err != nil {
+		return nil, err
+	}
+	p.client = p.newClient(pin)
+	return p.client.Authenticate(twoFaHandler)
+}
+
+// promptPin reads the pin from the terminal without echoing it.
+func promptPin() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", config.Errorf("no pin configured, set pin, pin-file or pin-command")
+	}
+
+	fmt.Fprint(os.Stderr, "PIN: ")
+	pin, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if len(pin) == 0 {
+		return "", errors.New("no pin entered")
+	}
+	return string(pin), nil
+}
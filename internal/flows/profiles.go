@@ -0,0 +1,50 @@
+package flows
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fbufler/comdirect/config"
+)
+
+// ProfileSession describes the cached session of a profile.
+type ProfileSession struct {
+	Profile   string     `json:"profile"`
+	Active    bool       `json:"active"`
+	Live      bool       `json:"live"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// Profiles lists all configured profiles and whether they have a live session in the token cache.
+// A session is live if its access token has not expired yet.
+func Profiles(cfg *config.Config) ([]ProfileSession, error) {
+	if !cfg.Cli.EnableCache {
+		return nil, config.Errorf("sessions are only kept if cli.enable-cache is set")
+	}
+
+	sessions := []ProfileSession{}
+	for _, profile := range cfg.ProfileNames() {
+		session := ProfileSession{
+			Profile: profile,
+			Active:  profile == cfg.Profile,
+		}
+
+		tokenCache, err := openProfileCache(cfg, profile)
+		if err != nil {
+			return nil, err
+		}
+		token, err := loadCache(tokenCache)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load token of profile %s: %w", profile, err)
+		}
+		if token != nil {
+			expiresAt := token.ExpiresAt()
+			session.ExpiresAt = &expiresAt
+			session.Live = !token.IsExpired()
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
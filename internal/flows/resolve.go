@@ -0,0 +1,214 @@
+package flows
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fbufler/comdirect/config"
+	"github.com/fbufler/comdirect/internal/cache"
+	"github.com/fbufler/comdirect/internal/listing"
+	"github.com/fbufler/comdirect/pkg/comdirect"
+)
+
+// candidate is an account or depot with all identifiers it can be referred to by.
+type candidate struct {
+	id          string
+	display     string
+	identifiers []string
+}
+
+// ResolveAccountID returns the account ID for an identifier.
+// The identifier is an account ID, IBAN, accountDisplayId, a configured alias or a unique prefix of one of them.
+// Accounts are looked up in the cached index first, the list is only requested if the index has no match
+// and the identifier is not an account ID already. The list is requested at most once per process.
+// Identifiers without any match are returned unchanged.
+func ResolveAccountID(cfg *config.Config, client comdirect.Banking, token *comdirect.AuthToken, identifier string) (string, error) {
+	return resolve(cfg, "account", identifier, func(index *cache.Index) []candidate {
+		return accountCandidates(index.Accounts)
+	}, func(index *cache.Index) error {
+		accounts, err := listAccounts(client, token)
+		index.Accounts = accounts
+		return err
+	})
+}
+
+// ResolveDepotID returns the depot ID for an identifier.
+// The identifier is a depot ID, depotDisplayId, a configured alias or a unique prefix of one of them, see ResolveAccountID.
+func ResolveDepotID(cfg *config.Config, client comdirect.Brokerage, token *comdirect.AuthToken, identifier string) (string, error) {
+	return resolve(cfg, "depot", identifier, func(index *cache.Index) []candidate {
+		return depotCandidates(index.Depots)
+	}, func(index *cache.Index) error {
+		depots, err := listDepots(client, token)
+		index.Depots = depots
+		return err
+	})
+}
+
+// refreshed holds the candidates of each kind requested by this process, the lists are requested at most once.
+var refreshed = struct {
+	sync.Mutex
+	candidates map[string][]candidate
+}{candidates: make(map[string][]candidate)}
+
+// plainIDPattern matches account and depot IDs, which are 32 hex characters.
+var plainIDPattern = regexp.MustCompile(`^[0-9A-Fa-f]{32}$`)
+
+func resolve(cfg *config.Config, kind string, identifier string, candidates func(index *cache.Index) []candidate, refresh func(index *cache.Index) error) (string, error) {
+	if target, ok := cfg.Client.Aliases[strings.ToLower(identifier)]; ok {
+		slog.Debug(fmt.Sprintf("Resolved alias %s to %s", identifier, target))
+		identifier = target
+	}
+
+	refreshed.Lock()
+	defer refreshed.Unlock()
+	if requested, ok := refreshed.candidates[kind]; ok {
+		return matchOrIdentifier(kind, identifier, requested)
+	}
+
+	indexCache, index := loadIndex(cfg)
+	if id, ok, err := match(kind, identifier, candidates(index)); ok || err != nil {
+		return id, err
+	}
+	if plainIDPattern.MatchString(identifier) {
+		slog.Debug(fmt.Sprintf("No cached %s matches %s, using it as ID", kind, identifier))
+		return identifier, nil
+	}
+
+	slog.Debug(fmt.Sprintf("No cached %s matches %s, refreshing the index", kind, identifier))
+	if err := refresh(index); err != nil {
+		return "", err
+	}
+	index.UpdatedAt = time.Now()
+	saveIndex(indexCache, index)
+	refreshed.candidates[kind] = candidates(index)
+
+	return matchOrIdentifier(kind, identifier, refreshed.candidates[kind])
+}
+
+// matchOrIdentifier returns the ID of the matching candidate, identifiers without any match are returned unchanged.
+func matchOrIdentifier(kind string, identifier string, candidates []candidate) (string, error) {
+	id, ok, err := match(kind, identifier, candidates)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		slog.Debug(fmt.Sprintf("No %s matches %s, using it as ID", kind, identifier))
+		return identifier, nil
+	}
+	return id, nil
+}
+
+// match returns the ID of the candidate with an identifier equal to the input, or else of the only candidate with an identifier starting with it.
+// Identifiers are compared case insensitive and without spaces, so IBANs may be grouped.
+func match(kind string, input string, candidates []candidate) (string, bool, error) {
+	input = normalizeIdentifier(input)
+	if input == "" {
+		return "", false, fmt.Errorf("empty %s identifier", kind)
+	}
+
+	var prefixed []candidate
+	for _, c := range candidates {
+		for _, identifier := range c.identifiers {
+			identifier = normalizeIdentifier(identifier)
+			if identifier == input {
+				return c.id, true, nil
+			}
+			if identifier != "" && strings.HasPrefix(identifier, input) && !slices.ContainsFunc(prefixed, func(p candidate) bool { return p.id == c.id }) {
+				prefixed = append(prefixed, c)
+			}
+		}
+	}
+
+	switch len(prefixed) {
+	case 0:
+		return "", false, nil
+	case 1:
+		return prefixed[0].id, true, nil
+	}
+	names := make([]string, len(prefixed))
+	for i, c := range prefixed {
+		names[i] = c.display
+	}
+	return "", false, fmt.Errorf("%s %q is ambiguous, it matches %s", kind, input, strings.Join(names, ", "))
+}
+
+func normalizeIdentifier(identifier string) string {
+	return strings.ToUpper(strings.ReplaceAll(identifier, " ", ""))
+}
+
+func accountCandidates(accounts []comdirect.Account) []candidate {
+	candidates := make([]candidate, len(accounts))
+	for i, account := range accounts {
+		candidates[i] = candidate{
+			id:          account.AccountID,
+			display:     fmt.Sprintf("%s (%s)", account.AccountDisplayID, account.AccountType.Text),
+			identifiers: []string{account.AccountID, account.IBAN, account.AccountDisplayID},
+		}
+	}
+	return candidates
+}
+
+func depotCandidates(depots []comdirect.Depot) []candidate {
+	candidates := make([]candidate, len(depots))
+	for i, depot := range depots {
+		candidates[i] = candidate{
+			id:          depot.DepotID,
+			display:     depot.DepotDisplayID,
+			identifiers: []string{depot.DepotID, depot.DepotDisplayID},
+		}
+	}
+	return candidates
+}
+
+func listAccounts(client comdirect.Banking, token *comdirect.AuthToken) ([]comdirect.Account, error) {
+	var accounts []comdirect.Account
+	for page, err := range AccountBalances(client, token, false, listing.Options{All: true}) {
+		if err != nil {
+			return nil, err
+		}
+		for _, balance := range page.Values {
+			accounts = append(accounts, balance.Account)
+		}
+	}
+	return accounts, nil
+}
+
+func listDepots(client comdirect.Brokerage, token *comdirect.AuthToken) ([]comdirect.Depot, error) {
+	var depots []comdirect.Depot
+	for page, err := range Depots(client, token, listing.Options{All: true}) {
+		if err != nil {
+			return nil, err
+		}
+		depots = append(depots, page.Values...)
+	}
+	return depots, nil
+}
+
+// loadIndex returns the cached index of the active profile.
+// Without cache, or if it can't be read, an empty index is returned and the cache is nil.
+func loadIndex(cfg *config.Config) (*cache.Cache, *cache.Index) {
+	indexCache, err := openCache(cfg)
+	if err != nil || indexCache == nil {
+		return nil, &cache.Index{}
+	}
+	index, err := indexCache.LoadIndex()
+	if err != nil {
+		slog.Warn(fmt.Sprintf("unable to load index from cache: %s", err))
+		return indexCache, &cache.Index{}
+	}
+	return indexCache, index
+}
+
+func saveIndex(indexCache *cache.Cache, index *cache.Index) {
+	if indexCache == nil {
+		return
+	}
+	if err := indexCache.SaveIndex(index); err != nil {
+		slog.Warn(fmt.Sprintf("unable to store index in cache: %s", err))
+	}
+}
@@ -0,0 +1,150 @@
+package flows
+
+import (
+	"iter"
+	"testing"
+
+	"github.com/fbufler/comdirect/config"
+	"github.com/fbufler/comdirect/internal/cache"
+	"github.com/fbufler/comdirect/pkg/comdirect"
+	"github.com/fbufler/comdirect/pkg/comdirect/comdirectmock"
+)
+
+const (
+	giroID    = "0123456789ABCDEF0123456789ABCDEF"
+	savingsID = "FEDCBA9876543210FEDCBA9876543210"
+)
+
+// resetRefreshed forgets the lists requested by earlier tests.
+func resetRefreshed(t *testing.T) {
+	t.Helper()
+	refreshed.Lock()
+	defer refreshed.Unlock()
+	refreshed.candidates = make(map[string][]candidate)
+}
+
+// newAccountsMock returns a mock listing a giro and a savings account and counts the requested lists.
+func newAccountsMock(requests *int) *comdirectmock.Client {
+	return &comdirectmock.Client{
+		AccountBalancePagesFunc: func(token *comdirect.AuthToken, options *comdirect.AccountBalancesOptions) iter.Seq2[*comdirect.AccountBalances, error] {
+			return func(yield func(*comdirect.AccountBalances, error) bool) {
+				*requests++
+				yield(&comdirect.AccountBalances{
+					Paging: comdirect.Paging{Matches: 2},
+					Values: []comdirect.AccountBalance{
+						{Account: comdirect.Account{AccountID: giroID, AccountDisplayID: "1111111111", IBAN: "DE02120300000000202051"}},
+						{Account: comdirect.Account{AccountID: savingsID, AccountDisplayID: "1122222222", IBAN: "DE02500105170137075030"}},
+					},
+				}, nil)
+			}
+		},
+	}
+}
+
+func TestResolveAccountID(t *testing.T) {
+	cfg := &config.Config{Client: config.ClientConfig{Aliases: map[string]string{"giro": "DE02120300000000202051"}}}
+
+	tests := []struct {
+		name       string
+		identifier string
+		want       string
+		wantErr    bool
+	}{
+		{"account ID", giroID, giroID, false},
+		{"IBAN", "DE02120300000000202051", giroID, false},
+		{"grouped IBAN", "de02 5001 0517 0137 0750 30", savingsID, false},
+		{"display ID", "1111111111", giroID, false},
+		{"alias", "Giro", giroID, false},
+		{"unique prefix", "1122", savingsID, false},
+		{"ambiguous prefix", "11", "", true},
+		{"no match", "unknown", "unknown", false},
+		{"empty", " ", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetRefreshed(t)
+			var requests int
+			got, err := ResolveAccountID(cfg, newAccountsMock(&requests), &comdirect.AuthToken{}, tt.identifier)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveAccountIDRefreshesOnce(t *testing.T) {
+	resetRefreshed(t)
+	var requests int
+	client := newAccountsMock(&requests)
+
+	for _, identifier := range []string{"unknown", "other", "1111111111"} {
+		if _, err := ResolveAccountID(&config.Config{}, client, &comdirect.AuthToken{}, identifier); err != nil {
+			t.Fatalf("resolve %s: %v", identifier, err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests of the account list, want 1", requests)
+	}
+}
+
+func TestResolveAccountIDSkipsRefreshForIDs(t *testing.T) {
+	resetRefreshed(t)
+	var requests int
+	got, err := ResolveAccountID(&config.Config{}, newAccountsMock(&requests), &comdirect.AuthToken{}, savingsID)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if got != savingsID {
+		t.Errorf("got %q, want %q", got, savingsID)
+	}
+	if requests != 0 {
+		t.Errorf("got %d requests of the account list, want none", requests)
+	}
+}
+
+func TestResolveDepotIDFromCachedIndex(t *testing.T) {
+	resetRefreshed(t)
+	cfg := newCacheConfig(t)
+	indexCache, err := openCache(cfg)
+	if err != nil {
+		t.Fatalf("open cache: %v", err)
+	}
+	if err := indexCache.SaveIndex(&cache.Index{Depots: []comdirect.Depot{{DepotID: giroID, DepotDisplayID: "3333333333"}}}); err != nil {
+		t.Fatalf("save index: %v", err)
+	}
+
+	// the depot list is not mocked, requesting it panics
+	got, err := ResolveDepotID(cfg, &comdirectmock.Client{}, &comdirect.AuthToken{}, "3333")
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if got != giroID {
+		t.Errorf("got %q, want %q", got, giroID)
+	}
+}
+
+func TestResolveDepotIDRefreshesCachedIndex(t *testing.T) {
+	resetRefreshed(t)
+	cfg := newCacheConfig(t)
+	client := &comdirectmock.Client{
+		DepotPagesFunc: func(authToken *comdirect.AuthToken, options *comdirect.DepotsOptions) iter.Seq2[*comdirect.Depots, error] {
+			return func(yield func(*comdirect.Depots, error) bool) {
+				yield(&comdirect.Depots{Values: []comdirect.Depot{{DepotID: savingsID, DepotDisplayID: "4444444444"}}}, nil)
+			}
+		},
+	}
+
+	got, err := ResolveDepotID(cfg, client, &comdirect.AuthToken{}, "4444444444")
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if got != savingsID {
+		t.Errorf("got %q, want %q", got, savingsID)
+	}
+	if index := CachedIndex(cfg); len(index.Depots) != 1 || index.Depots[0].DepotID != savingsID {
+		t.Errorf("got cached depots %+v, want the requested depot", index.Depots)
+	}
+}
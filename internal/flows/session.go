@@ -0,0 +1,130 @@
+package flows
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/fbufler/comdirect/config"
+	"github.com/fbufler/comdirect/internal/cache"
+)
+
+// SessionStatus describes the cached session of the active profile.
+type SessionStatus struct {
+	Profile               string    `json:"profile"`
+	SessionGUID           string    `json:"sessionGUID"`
+	TANType               string    `json:"tanType"`
+	Scope                 string    `json:"scope"`
+	Expired               bool      `json:"expired"`
+	ExpiresAt             time.Time `json:"expiresAt"`
+	ExpiresIn             string    `json:"expiresIn"`
+	RefreshTokenExpiresAt time.Time `json:"refreshTokenExpiresAt"`
+	RefreshTokenExpiresIn string    `json:"refreshTokenExpiresIn"`
+}
+
+// Login authenticates the active profile and keeps the session in the token cache.
+// A live cached session is reused, unless force is set.
+func Login(cfg *config.Config, force bool) (*SessionStatus, error) {
+	tokenCache, err := requireCache(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if force {
+		if err := deleteSession(tokenCache); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, _, err := Bootstrap(cfg); err != nil {
+		return nil, err
+	}
+	return Session(cfg)
+}
+
+// deleteSession removes the cached session while holding the lock, so a concurrent run doesn't store a token in between.
+// The lock is released afterwards, as the login takes it again.
+func deleteSession(tokenCache *cache.Cache) error {
+	unlock, err := tokenCache.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return tokenCache.Delete()
+}
+
+// Logout revokes the session of the active profile and removes it from the token cache.
+// The cache is wiped even if revoking fails, e.g. because the session already expired.
+func Logout(cfg *config.Config) error {
+	tokenCache, err := requireCache(cfg)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := tokenCache.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	token, err := loadCache(tokenCache)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("unable to load token from cache: %s", err))
+	}
+
+	if token != nil {
+		clientConfig, err := cfg.Client.ResolveSecrets()
+		if err != nil {
+			return err
+		}
+		if err := newClient(clientConfig).RevokeToken(token); err != nil {
+			slog.Warn(fmt.Sprintf("unable to revoke token: %s", err))
+		}
+	}
+
+	return tokenCache.Delete()
+}
+
+// Session returns the status of the cached session of the active profile, no request is sent.
+func Session(cfg *config.Config) (*SessionStatus, error) {
+	tokenCache, err := requireCache(cfg)
+	if err != nil {
+		return nil, err
+	}
+	token, err := loadCache(tokenCache)
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, ErrNoSession
+	}
+
+	now := time.Now()
+	status := SessionStatus{
+		Profile:               cfg.Profile,
+		SessionGUID:           token.SessionGUID,
+		TANType:               token.TANType,
+		Scope:                 token.Scope,
+		Expired:               token.IsExpired(),
+		ExpiresAt:             token.ExpiresAt(),
+		ExpiresIn:             remaining(now, token.ExpiresAt()),
+		RefreshTokenExpiresAt: token.RefreshExpiresAt(),
+		RefreshTokenExpiresIn: remaining(now, token.RefreshExpiresAt()),
+	}
+
+	return &status, nil
+}
+
+// requireCache returns the token cache of the active profile, sessions can't be kept without it.
+func requireCache(cfg *config.Config) (*cache.Cache, error) {
+	if !cfg.Cli.EnableCache {
+		return nil, config.Errorf("sessions are only kept if cli.enable-cache is set")
+	}
+	return openCache(cfg)
+}
+
+func remaining(now time.Time, expiresAt time.Time) string {
+	if !expiresAt.After(now) {
+		return "expired"
+	}
+	return expiresAt.Sub(now).Round(time.Second).String()
+}
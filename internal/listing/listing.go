@@ -0,0 +1,132 @@
+// Package listing provides the flags selecting which values of a list command are requested.
+package listing
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fbufler/comdirect/config"
+	"github.com/fbufler/comdirect/internal/convert"
+	"github.com/spf13/cobra"
+)
+
+// Options select the values of a list.
+// Without Count and All only the first page is requested, or with Since or Until the pages up to the date bound, see ReachedBound.
+// Since and Until only apply to lists with a booking date, zero values don't filter.
+type Options struct {
+	Count    int
+	PageSize int
+	All      bool
+	Since    time.Time
+	Until    time.Time
+}
+
+// Paginated reports whether more than the first page may be requested.
+func (o Options) Paginated() bool {
+	return o.All || o.Count > 0
+}
+
+// AddFlags adds --count, --page-size and --all to a list command.
+func AddFlags(cmd *cobra.Command) {
+	cmd.Flags().IntP("count", "c", 0, "Amount of values to return, requested over as many pages as needed")
+	cmd.Flags().Int("page-size", 0, "Amount of values per request, by default the API default of 20")
+	cmd.Flags().Bool("all", false, "Return all values, requested page by page")
+	cmd.MarkFlagsMutuallyExclusive("count", "all")
+}
+
+// AddDateFlags adds --since and --until to a list command with a booking date.
+func AddDateFlags(cmd *cobra.Command) {
+	cmd.Flags().String("since", "", "Only values booked on or after the first day of the date, "+convert.DateExpressions)
+	cmd.Flags().String("until", "", "Only values booked on or before the last day of the date, "+convert.DateExpressions)
+}
+
+// OptionsFromFlags reads the options from the flags added with AddFlags and AddDateFlags.
+func OptionsFromFlags(cmd *cobra.Command) (Options, error) {
+	var options Options
+	var err error
+	if options.Count, err = cmd.Flags().GetInt("count"); err != nil {
+		return Options{}, err
+	}
+	if options.PageSize, err = cmd.Flags().GetInt("page-size"); err != nil {
+		return Options{}, err
+	}
+	if options.All, err = cmd.Flags().GetBool("all"); err != nil {
+		return Options{}, err
+	}
+	if options.Count < 0 || options.PageSize < 0 {
+		return Options{}, fmt.Errorf("count and page-size must not be negative")
+	}
+	now := time.Now()
+	if options.Since, err = dateFlag(cmd, "since", func(expression string) (time.Time, error) {
+		return convert.StartDate(expression, now)
+	}); err != nil {
+		return Options{}, err
+	}
+	if options.Until, err = EndDateFlag(cmd, "until", now); err != nil {
+		return Options{}, err
+	}
+	if !options.Since.IsZero() && !options.Until.IsZero() && options.Until.Before(options.Since) {
+		return Options{}, fmt.Errorf("until %s is before since %s", options.Until.Format(time.DateOnly), options.Since.Format(time.DateOnly))
+	}
+	return options, nil
+}
+
+// EndDateFlag resolves the date expression of a flag to the last day of the date, see convert.EndDate.
+// An unset flag returns the zero time.
+func EndDateFlag(cmd *cobra.Command, name string, now time.Time) (time.Time, error) {
+	return dateFlag(cmd, name, func(expression string) (time.Time, error) {
+		return convert.EndDate(expression, now)
+	})
+}
+
+// dateFlag resolves the date expression of a flag with resolve, an unset flag returns the zero time.
+// An invalid expression is a config error prefixed with the flag, like any other invalid flag.
+func dateFlag(cmd *cobra.Command, name string, resolve func(expression string) (time.Time, error)) (time.Time, error) {
+	flag := cmd.Flags().Lookup(name)
+	if flag == nil || flag.Value.String() == "" {
+		return time.Time{}, nil
+	}
+	date, err := resolve(flag.Value.String())
+	if err != nil {
+		return time.Time{}, config.Errorf("--%s: %w", name, err)
+	}
+	return date, nil
+}
+
+// Includes reports whether a booking date in the format 2006-01-02 is within Since and Until.
+// Values without a parseable booking date, e.g. pending transactions, are only included without a date filter.
+func (o Options) Includes(bookingDate string) bool {
+	if o.Since.IsZero() && o.Until.IsZero() {
+		return true
+	}
+	date, err := time.Parse(time.DateOnly, bookingDate)
+	if err != nil {
+		return false
+	}
+	if !o.Since.IsZero() && date.Before(o.Since) {
+		return false
+	}
+	if !o.Until.IsZero() && date.After(o.Until) {
+		return false
+	}
+	return true
+}
+
+// Bounded reports whether Since or Until is set.
+func (o Options) Bounded() bool {
+	return !o.Since.IsZero() || !o.Until.IsZero()
+}
+
+// ReachedBound reports whether a value of a list ordered by booking date, newest first, reached the date bound.
+// With Since it is reached by a value booked before it, as all later values are older.
+// With only Until it is reached by a value booked on or before it, as the values within Until start there.
+func (o Options) ReachedBound(bookingDate string) bool {
+	date, err := time.Parse(time.DateOnly, bookingDate)
+	if err != nil {
+		return false
+	}
+	if !o.Since.IsZero() {
+		return date.Before(o.Since)
+	}
+	return !o.Until.IsZero() && !date.After(o.Until)
+}
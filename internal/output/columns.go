@@ -0,0 +1,178 @@
+package output
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/fbufler/comdirect/pkg/comdirect"
+)
+
+// Column addresses a field of a row by its JSON path, Fallback is used if the field is empty.
+// The header defaults to the name, hidden columns are only shown if they are selected with --columns.
+type Column struct {
+	Name     string
+	Path     string
+	Fallback string
+	Header   string
+	Hidden   bool
+}
+
+// value returns the field of the row addressed by the column.
+func (c Column) value(row map[string]any) any {
+	value := lookup(row, c.Path)
+	if c.Fallback != "" && (value == nil || value == "") {
+		return lookup(row, c.Fallback)
+	}
+	return value
+}
+
+func (c Column) header() string {
+	if c.Header != "" {
+		return c.Header
+	}
+	return strings.ToUpper(strings.ReplaceAll(c.Name, "-", " "))
+}
+
+// resources are the columns of the rows of each response, the visible columns are the defaults.
+var resources = map[reflect.Type][]Column{
+	reflect.TypeOf(comdirect.AccountBalance{}): {
+		{Name: "name", Path: "account.accountType.text"},
+		{Name: "iban", Path: "account.iban"},
+		{Name: "balance", Path: "balance"},
+		{Name: "available", Path: "availableCashAmount"},
+		{Name: "id", Path: "accountId", Hidden: true},
+		{Name: "display-id", Path: "account.accountDisplayId", Hidden: true},
+		{Name: "currency", Path: "account.currency", Hidden: true},
+		{Name: "credit-limit", Path: "account.creditLimit", Hidden: true},
+	},
+	reflect.TypeOf(comdirect.AccountTransaction{}): {
+		{Name: "date", Path: "bookingDate"},
+		{Name: "status", Path: "bookingStatus"},
+		{Name: "type", Path: "transactionType.text"},
+		// incoming payments have a remitter instead of a creditor
+		{Name: "counterparty", Path: "creditor.holderName", Fallback: "remitter.holderName"},
+		{Name: "amount", Path: "amount"},
+		{Name: "creditor", Path: "creditor.holderName", Hidden: true},
+		{Name: "remitter", Path: "remitter.holderName", Hidden: true},
+		{Name: "remittance-info", Path: "remittanceInfo", Hidden: true},
+		{Name: "valuta-date", Path: "valutaDate", Hidden: true},
+		{Name: "reference", Path: "reference", Hidden: true},
+	},
+	reflect.TypeOf(comdirect.Depot{}): {
+		{Name: "id", Path: "depotId"},
+		{Name: "display-id", Path: "depotDisplayId"},
+		{Name: "type", Path: "depotType"},
+		{Name: "settlement-account", Path: "defaultSettlementAccountId"},
+	},
+	reflect.TypeOf(comdirect.DepotPosition{}): {
+		{Name: "wkn", Path: "wkn"},
+		{Name: "name", Path: "instrument.shortName"},
+		{Name: "quantity", Path: "quantity"},
+		{Name: "price", Path: "currentPrice.price"},
+		{Name: "value", Path: "currentValue"},
+		{Name: "pl", Path: "profitLossPurchaseAbs", Header: "P/L"},
+		{Name: "pl-percent", Path: "profitLossPurchaseRel", Header: "P/L %"},
+		{Name: "id", Path: "positionId", Hidden: true},
+		{Name: "purchase-value", Path: "purchaseValue", Hidden: true},
+		{Name: "isin", Path: "instrument.isin", Hidden: true},
+		{Name: "purchase-price", Path: "purchasePrice", Hidden: true},
+		{Name: "pl-day", Path: "profitLossPrevDayAbs", Header: "P/L DAY", Hidden: true},
+		{Name: "pl-day-percent", Path: "profitLossPrevDayRel", Header: "P/L DAY %", Hidden: true},
+	},
+	reflect.TypeOf(comdirect.DepotTransaction{}): {
+		{Name: "date", Path: "bookingDate"},
+		{Name: "type", Path: "transactionType"},
+		{Name: "wkn", Path: "instrument.wkn"},
+		{Name: "name", Path: "instrument.shortName"},
+		{Name: "quantity", Path: "quantity"},
+		{Name: "price", Path: "executionPrice.price"},
+		{Name: "value", Path: "transactionValue"},
+		{Name: "id", Path: "transactionId", Hidden: true},
+		{Name: "isin", Path: "instrument.isin", Hidden: true},
+		{Name: "status", Path: "bookingStatus", Hidden: true},
+	},
+	reflect.TypeOf(comdirect.Document{}): {
+		{Name: "date", Path: "dateCreation"},
+		{Name: "name", Path: "name"},
+		{Name: "type", Path: "mimeType"},
+		{Name: "read", Path: "documentMetaData.alreadyRead"},
+		{Name: "id", Path: "documentId"},
+	},
+}
+
+// selectColumns returns the columns selected by name or path, or the default columns of the row type.
+func selectColumns(rowType reflect.Type, names []string) ([]Column, error) {
+	if len(names) == 0 {
+		return defaultColumns(rowType), nil
+	}
+	columns := make([]Column, 0, len(names))
+	for _, name := range names {
+		column, err := resolveColumn(rowType, name)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// resolveColumn returns the column with the name or Go field path like Amount.Value, any other name is used as JSON field path.
+func resolveColumn(rowType reflect.Type, name string) (Column, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Column{}, fmt.Errorf("empty column name")
+	}
+	for _, column := range append(resourceColumns(rowType), flatColumns(rowType)...) {
+		if strings.EqualFold(column.Name, name) {
+			return column, nil
+		}
+	}
+	return Column{Name: name, Path: name}, nil
+}
+
+func defaultColumns(rowType reflect.Type) []Column {
+	var columns []Column
+	for _, column := range resourceColumns(rowType) {
+		if !column.Hidden {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// resourceColumns returns the registered columns of the row type.
+// Unregistered types get a column for each scalar field.
+func resourceColumns(rowType reflect.Type) []Column {
+	if rowType == nil {
+		return nil
+	}
+	if columns, ok := resources[rowType]; ok {
+		return columns
+	}
+	if rowType.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var columns []Column
+	for i := 0; i < rowType.NumField(); i++ {
+		field := rowType.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || field.Anonymous || name == "-" || name == "" {
+			continue
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct && fieldType != reflect.TypeOf(time.Time{}) {
+			continue
+		}
+		if fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Map {
+			continue
+		}
+		columns = append(columns, Column{Name: name, Path: name})
+	}
+	return columns
+}
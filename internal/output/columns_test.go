@@ -0,0 +1,48 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fbufler/comdirect/pkg/comdirect"
+)
+
+func testTransactions() *comdirect.AccountTransactions {
+	return &comdirect.AccountTransactions{Values: []comdirect.AccountTransaction{
+		{BookingDate: "2024-01-31", Creditor: comdirect.Creditor{HolderName: "Stadtwerke"}, Amount: comdirect.Balance{Value: "-12.5", Unit: "EUR"}},
+		{BookingDate: "2024-01-30", Remitter: comdirect.AccountInformation{HolderName: "Erika Mustermann", IBAN: "DE02120300000000202051"}, Amount: comdirect.Balance{Value: "100", Unit: "EUR"}},
+	}}
+}
+
+func TestTableCounterparty(t *testing.T) {
+	var out strings.Builder
+	if err := Write(&out, testTransactions(), Options{Format: FormatTable}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"COUNTERPARTY", "Stadtwerke", "Erika Mustermann"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("table does not contain %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestCSVRemitterHolderName(t *testing.T) {
+	var out strings.Builder
+	if err := Write(&out, testTransactions(), Options{Format: FormatCSV}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(out.String(), "\n")
+	headers := strings.Split(lines[0], ",")
+	column := -1
+	for i, header := range headers {
+		if header == "Remitter.HolderName" {
+			column = i
+		}
+	}
+	if column < 0 {
+		t.Fatalf("no Remitter.HolderName header in %s", lines[0])
+	}
+	if got := strings.Split(lines[2], ",")[column]; got != "Erika Mustermann" {
+		t.Errorf("got remitter %q, want %q", got, "Erika Mustermann")
+	}
+}
@@ -0,0 +1,97 @@
+package output
+
+import (
+	"encoding/csv"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// newDelimitedTable returns a table for csv and tsv.
+// Without selected columns every field is flattened into its own column, headers are the Go field paths like Amount.Value,
+// so they don't depend on which fields are set in a response.
+func newDelimitedTable(data any, options Options) (*table, error) {
+	t, err := newTable(data, options)
+	if err != nil {
+		return nil, err
+	}
+	if len(options.Columns) == 0 {
+		t.columns = flatColumns(rowType(reflect.TypeOf(data)))
+	}
+	return t, nil
+}
+
+func (t *table) writeDelimited(w io.Writer, options Options) error {
+	writer := csv.NewWriter(w)
+	if options.Delimiter != 0 {
+		writer.Comma = options.Delimiter
+	}
+
+	headers := make([]string, len(t.columns))
+	for i, column := range t.columns {
+		headers[i] = column.Name
+	}
+	if err := writer.Write(headers); err != nil {
+		return err
+	}
+
+	record := make([]string, len(t.columns))
+	for _, row := range t.rows {
+		for i, column := range t.columns {
+			record[i] = delimitedCell(column.value(row), options.DecimalComma)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// delimitedCell formats a value for csv, amounts are written without unit so spreadsheets treat them as numbers.
+func delimitedCell(value any, decimalComma bool) string {
+	text := sortValue(value)
+	if decimalComma && strings.Contains(text, ".") {
+		if _, err := strconv.ParseFloat(text, 64); err == nil {
+			text = strings.Replace(text, ".", ",", 1)
+		}
+	}
+	return text
+}
+
+// flatColumns returns a column for every leaf field of the row type, named by its Go field path.
+func flatColumns(rowType reflect.Type) []Column {
+	var columns []Column
+	if rowType == nil || rowType.Kind() != reflect.Struct {
+		return columns
+	}
+	walkFields(rowType, "", "", func(name string, path string) {
+		columns = append(columns, Column{Name: name, Path: path})
+	})
+	return columns
+}
+
+func walkFields(t reflect.Type, namePrefix string, pathPrefix string, fn func(name string, path string)) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || field.Anonymous || jsonName == "-" || jsonName == "" {
+			continue
+		}
+		name := namePrefix + field.Name
+		path := pathPrefix + jsonName
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct && fieldType != reflect.TypeOf(time.Time{}) {
+			walkFields(fieldType, name+".", path+".", fn)
+			continue
+		}
+		fn(name, path)
+	}
+}
@@ -0,0 +1,392 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The jsonpath output supports the subset of the kubectl JSONPath syntax which is useful for the responses:
+//
+//	{.values[*].accountId}        fields, wildcards and indexes, also .*, [0], [-1], [1:3] and ['name']
+//	{..iban}                      recursive descent
+//	{range .values[*]}...{end}    iterate over the results, paths inside are relative to the current item
+//	{"\t"}                        string literals
+//
+// Filters and functions are not supported. Multiple results of a path are separated by a space.
+
+// jsonPathSyntax summarizes the supported syntax for the help of --output.
+const jsonPathSyntax = "jsonpath supports fields {.values[*].accountId}, wildcards .* and [*], indexes [0] and [-1], " +
+	"slices [1:3], quoted names ['name'], recursive descent {..iban}, {range .values[*]}...{end} and literals {\"\\t\"}, " +
+	"filters and functions are not supported"
+
+type jsonPathNode struct {
+	text     string
+	literal  bool
+	path     []pathStep
+	isRange  bool
+	children []*jsonPathNode
+}
+
+type stepKind int
+
+const (
+	stepRoot stepKind = iota
+	stepField
+	stepRecursive
+	stepWildcard
+	stepIndex
+	stepSlice
+)
+
+type pathStep struct {
+	kind       stepKind
+	name       string
+	index      int
+	start, end *int
+}
+
+func writeJSONPath(w io.Writer, data any, template string) error {
+	nodes, err := parseJSONPath(template)
+	if err != nil {
+		return err
+	}
+	root, err := generic(data)
+	if err != nil {
+		return err
+	}
+	var out strings.Builder
+	if err := executeJSONPath(&out, nodes, root, root); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, out.String())
+	return err
+}
+
+func executeJSONPath(out *strings.Builder, nodes []*jsonPathNode, root any, current any) error {
+	for _, node := range nodes {
+		switch {
+		case node.literal:
+			out.WriteString(node.text)
+		case node.isRange:
+			results, err := evaluatePath(node.path, root, current)
+			if err != nil {
+				return err
+			}
+			for _, result := range results {
+				if err := executeJSONPath(out, node.children, root, result); err != nil {
+					return err
+				}
+			}
+		default:
+			results, err := evaluatePath(node.path, root, current)
+			if err != nil {
+				return err
+			}
+			texts := make([]string, len(results))
+			for i, result := range results {
+				texts[i] = jsonPathText(result)
+			}
+			out.WriteString(strings.Join(texts, " "))
+		}
+	}
+	return nil
+}
+
+func jsonPathText(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	raw, _ := json.Marshal(value)
+	return string(raw)
+}
+
+// parseJSONPath splits the template into text and expressions and nests the nodes of range blocks.
+func parseJSONPath(template string) ([]*jsonPathNode, error) {
+	root := &jsonPathNode{}
+	stack := []*jsonPathNode{root}
+	add := func(node *jsonPathNode) {
+		parent := stack[len(stack)-1]
+		parent.children = append(parent.children, node)
+	}
+
+	for len(template) > 0 {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			add(&jsonPathNode{text: template, literal: true})
+			break
+		}
+		if start > 0 {
+			add(&jsonPathNode{text: template[:start], literal: true})
+		}
+		end, err := closingBrace(template, start)
+		if err != nil {
+			return nil, err
+		}
+		expression := strings.TrimSpace(template[start+1 : end])
+		template = template[end+1:]
+
+		switch {
+		case expression == "end":
+			if len(stack) == 1 {
+				return nil, fmt.Errorf("jsonpath: {end} without {range}")
+			}
+			stack = stack[:len(stack)-1]
+		case strings.HasPrefix(expression, "range "):
+			path, err := parsePath(strings.TrimSpace(strings.TrimPrefix(expression, "range ")))
+			if err != nil {
+				return nil, err
+			}
+			node := &jsonPathNode{path: path, isRange: true}
+			add(node)
+			stack = append(stack, node)
+		case strings.HasPrefix(expression, `"`) || strings.HasPrefix(expression, "'"):
+			text, err := unquote(expression)
+			if err != nil {
+				return nil, fmt.Errorf("jsonpath: invalid string literal %s", expression)
+			}
+			add(&jsonPathNode{text: text, literal: true})
+		default:
+			path, err := parsePath(expression)
+			if err != nil {
+				return nil, err
+			}
+			add(&jsonPathNode{path: path})
+		}
+	}
+
+	if len(stack) > 1 {
+		return nil, fmt.Errorf("jsonpath: {range} without {end}")
+	}
+	return root.children, nil
+}
+
+// closingBrace returns the index of the brace closing the expression starting at start, braces in quotes are ignored.
+func closingBrace(template string, start int) (int, error) {
+	var quote byte
+	for i := start + 1; i < len(template); i++ {
+		c := template[i]
+		switch {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == 0 && c == '}':
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("jsonpath: unclosed expression %s", template[start:])
+}
+
+func unquote(literal string) (string, error) {
+	if strings.HasPrefix(literal, "'") {
+		literal = `"` + strings.ReplaceAll(strings.Trim(literal, "'"), `"`, `\"`) + `"`
+	}
+	return strconv.Unquote(literal)
+}
+
+func parsePath(path string) ([]pathStep, error) {
+	invalid := func() ([]pathStep, error) {
+		return nil, fmt.Errorf("jsonpath: invalid path %q", path)
+	}
+
+	var steps []pathStep
+	rest := path
+	if strings.HasPrefix(rest, "$") {
+		steps = append(steps, pathStep{kind: stepRoot})
+		rest = rest[1:]
+	}
+	rest = strings.TrimPrefix(rest, "@")
+	for len(rest) > 0 {
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			name, remaining := pathName(rest[2:])
+			if name == "" {
+				return invalid()
+			}
+			steps = append(steps, pathStep{kind: stepRecursive, name: name})
+			rest = remaining
+		case strings.HasPrefix(rest, ".*"):
+			steps = append(steps, pathStep{kind: stepWildcard})
+			rest = rest[2:]
+		case strings.HasPrefix(rest, "."):
+			name, remaining := pathName(rest[1:])
+			if name != "" {
+				steps = append(steps, pathStep{kind: stepField, name: name})
+			}
+			rest = remaining
+		case strings.HasPrefix(rest, "["):
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return invalid()
+			}
+			step, err := parseSubscript(strings.TrimSpace(rest[1:end]))
+			if err != nil {
+				return nil, fmt.Errorf("jsonpath: invalid path %q: %w", path, err)
+			}
+			steps = append(steps, step)
+			rest = rest[end+1:]
+		default:
+			return invalid()
+		}
+	}
+	return steps, nil
+}
+
+func pathName(path string) (name string, rest string) {
+	end := strings.IndexAny(path, ".[")
+	if end < 0 {
+		return path, ""
+	}
+	return path[:end], path[end:]
+}
+
+func parseSubscript(subscript string) (pathStep, error) {
+	switch {
+	case subscript == "*":
+		return pathStep{kind: stepWildcard}, nil
+	case strings.HasPrefix(subscript, "'") || strings.HasPrefix(subscript, `"`):
+		name, err := unquote(subscript)
+		return pathStep{kind: stepField, name: name}, err
+	case strings.Contains(subscript, ":"):
+		from, to, _ := strings.Cut(subscript, ":")
+		step := pathStep{kind: stepSlice}
+		if from != "" {
+			start, err := strconv.Atoi(from)
+			if err != nil {
+				return step, err
+			}
+			step.start = &start
+		}
+		if to != "" {
+			end, err := strconv.Atoi(to)
+			if err != nil {
+				return step, err
+			}
+			step.end = &end
+		}
+		return step, nil
+	}
+	index, err := strconv.Atoi(subscript)
+	return pathStep{kind: stepIndex, index: index}, err
+}
+
+// evaluatePath returns all values matched by the path, paths starting with $ are evaluated on the root.
+func evaluatePath(steps []pathStep, root any, current any) ([]any, error) {
+	values := []any{current}
+	for _, step := range steps {
+		if step.kind == stepRoot {
+			values = []any{root}
+			continue
+		}
+		var next []any
+		for _, value := range values {
+			results, err := evaluateStep(step, value)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, results...)
+		}
+		values = next
+	}
+	return values, nil
+}
+
+func evaluateStep(step pathStep, value any) ([]any, error) {
+	switch step.kind {
+	case stepField:
+		if object, ok := value.(map[string]any); ok {
+			if field, ok := object[step.name]; ok {
+				return []any{field}, nil
+			}
+		}
+		return nil, nil
+	case stepRecursive:
+		return descendants(step.name, value), nil
+	case stepWildcard:
+		return children(value), nil
+	case stepIndex:
+		list, ok := value.([]any)
+		if !ok {
+			return nil, nil
+		}
+		index := step.index
+		if index < 0 {
+			index += len(list)
+		}
+		if index < 0 || index >= len(list) {
+			return nil, fmt.Errorf("jsonpath: index %d out of range, the array has %d elements", step.index, len(list))
+		}
+		return []any{list[index]}, nil
+	case stepSlice:
+		list, ok := value.([]any)
+		if !ok {
+			return nil, nil
+		}
+		start, end := 0, len(list)
+		if step.start != nil {
+			start = clampIndex(*step.start, len(list))
+		}
+		if step.end != nil {
+			end = clampIndex(*step.end, len(list))
+		}
+		if start >= end {
+			return nil, nil
+		}
+		return list[start:end], nil
+	}
+	return nil, nil
+}
+
+func clampIndex(index int, length int) int {
+	if index < 0 {
+		index += length
+	}
+	return min(max(index, 0), length)
+}
+
+// children returns the elements of an array or the values of an object ordered by key.
+func children(value any) []any {
+	switch v := value.(type) {
+	case []any:
+		return v
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		values := make([]any, len(keys))
+		for i, key := range keys {
+			values[i] = v[key]
+		}
+		return values
+	}
+	return nil
+}
+
+// descendants returns the values of all fields with the name in value and its children.
+func descendants(name string, value any) []any {
+	var results []any
+	if object, ok := value.(map[string]any); ok {
+		if field, ok := object[name]; ok {
+			results = append(results, field)
+		}
+	}
+	for _, child := range children(value) {
+		results = append(results, descendants(name, child)...)
+	}
+	return results
+}
@@ -0,0 +1,101 @@
+package output
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const jsonPathData = `{
+	"paging": {"index": 0, "matches": 3},
+	"values": [
+		{"accountId": "A1", "account": {"iban": "DE01"}, "balance": {"value": "1.50", "unit": "EUR"}, "open": true},
+		{"accountId": "A2", "account": {"iban": "DE02"}, "balance": {"value": "-2", "unit": "EUR"}, "open": false},
+		{"accountId": "A3", "account": {"iban": "DE03"}, "balance": {"value": "0", "unit": "USD"}, "open": null}
+	],
+	"odd key": "spaced"
+}`
+
+func jsonPathInput(t *testing.T) any {
+	t.Helper()
+	var data any
+	if err := json.Unmarshal([]byte(jsonPathData), &data); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return data
+}
+
+func TestWriteJSONPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"field", "{.paging.matches}", "3"},
+		{"root", "{$.paging.index}", "0"},
+		{"wildcard index", "{.values[*].accountId}", "A1 A2 A3"},
+		{"wildcard field", "{.paging.*}", "0 3"},
+		{"index", "{.values[1].accountId}", "A2"},
+		{"negative index", "{.values[-1].accountId}", "A3"},
+		{"slice", "{.values[1:3].accountId}", "A2 A3"},
+		{"open slice", "{.values[:2].accountId}", "A1 A2"},
+		{"negative slice", "{.values[-2:].accountId}", "A2 A3"},
+		{"empty slice", "{.values[2:1].accountId}", ""},
+		{"quoted field", "{['odd key']}", "spaced"},
+		{"double quoted field", `{.values[0]["accountId"]}`, "A1"},
+		{"recursive descent", "{..iban}", "DE01 DE02 DE03"},
+		{"missing field", "{.values[0].missing}", ""},
+		{"object", "{.values[0].balance}", `{"unit":"EUR","value":"1.50"}`},
+		{"bool and null", "{.values[*].open}", "true false "},
+		{"text around", "matches: {.paging.matches}!", "matches: 3!"},
+		{"string literal", `{.values[0].accountId}{"\t"}{.values[1].accountId}`, "A1\tA2"},
+		{"single quoted literal", `{'a "b"'}`, `a "b"`},
+		{"brace in literal", `{"}"}`, "}"},
+		{"range", `{range .values[*]}{.accountId}={.balance.value}{"\n"}{end}`, "A1=1.50\nA2=-2\nA3=0\n"},
+		{"range with root", `{range .values[0:2]}{.accountId}/{$.paging.matches} {end}`, "A1/3 A2/3 "},
+		{"nested range", `{range .values[0:2]}{range .balance.*}{@}{";"}{end}{end}`, "EUR;1.50;EUR;-2;"},
+		{"without expression", "plain", "plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			if err := writeJSONPath(&out, jsonPathInput(t), tt.template); err != nil {
+				t.Fatalf("write %s: %v", tt.template, err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteJSONPathErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"unclosed expression", "{.values", "unclosed expression"},
+		{"end without range", "{end}", "{end} without {range}"},
+		{"range without end", "{range .values[*]}{.accountId}", "{range} without {end}"},
+		{"invalid literal", `{"\q"}`, "invalid string literal"},
+		{"invalid path", "{values}", "invalid path"},
+		{"empty recursive descent", "{..}", "invalid path"},
+		{"unclosed subscript", "{.values[0}", "invalid path"},
+		{"invalid index", "{.values[x]}", "invalid path"},
+		{"invalid slice", "{.values[1:x]}", "invalid path"},
+		{"index out of range", "{.values[3]}", "index 3 out of range"},
+		{"negative index out of range", "{.values[-4]}", "index -4 out of range"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := writeJSONPath(&strings.Builder{}, jsonPathInput(t), tt.template)
+			if err == nil {
+				t.Fatalf("write %s: got no error", tt.template)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
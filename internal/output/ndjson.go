@@ -0,0 +1,109 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"log/slog"
+	"reflect"
+
+	"github.com/spf13/cobra"
+)
+
+// PrintPages writes the pages of a list to the output of the command.
+// The ndjson format writes the records of each page as soon as it arrives, so memory stays constant for long lists.
+// All other formats need the whole list, the pages are merged into the first one before it is written.
+func PrintPages[P any](cmd *cobra.Command, pages iter.Seq2[P, error]) error {
+	options, err := OptionsFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	if options.Format == FormatNDJSON {
+		slog.Debug(fmt.Sprintf("Output format: %s, streaming pages", options.Format))
+		for page, err := range pages {
+			if err != nil {
+				return err
+			}
+			if err := writeNDJSON(cmd.OutOrStdout(), page); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var merged P
+	first := true
+	for page, err := range pages {
+		if err != nil {
+			return err
+		}
+		if first {
+			merged, first = page, false
+			continue
+		}
+		if err := appendValues(merged, page); err != nil {
+			return err
+		}
+	}
+	return Write(cmd.OutOrStdout(), merged, options)
+}
+
+// writeNDJSON writes every record of the data as compact JSON on its own line.
+func writeNDJSON(w io.Writer, data any) error {
+	encoder := json.NewEncoder(w)
+	for _, record := range records(data) {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// records returns the elements of the Values field of a list response, the elements of a slice or the data itself.
+func records(data any) []any {
+	value := reflect.ValueOf(data)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.Struct {
+		value = value.FieldByName("Values")
+		if !value.IsValid() || value.Kind() != reflect.Slice {
+			return []any{data}
+		}
+	}
+	if value.Kind() != reflect.Slice {
+		return []any{data}
+	}
+	records := make([]any, value.Len())
+	for i := range records {
+		records[i] = value.Index(i).Interface()
+	}
+	return records
+}
+
+// appendValues appends the Values of the page src to the Values of the page dst.
+func appendValues(dst any, src any) error {
+	dstValues := valuesField(dst)
+	srcValues := valuesField(src)
+	if !dstValues.IsValid() || !srcValues.IsValid() || !dstValues.CanSet() {
+		return fmt.Errorf("cannot merge pages of type %T", dst)
+	}
+	dstValues.Set(reflect.AppendSlice(dstValues, srcValues))
+	return nil
+}
+
+func valuesField(page any) reflect.Value {
+	value := reflect.ValueOf(page)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	values := value.Elem().FieldByName("Values")
+	if values.Kind() != reflect.Slice {
+		return reflect.Value{}
+	}
+	return values
+}
@@ -0,0 +1,161 @@
+// Package output renders the responses of the commands in the format selected with --output.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/fbufler/comdirect/internal/convert"
+	"github.com/spf13/cobra"
+)
+
+// Formats supported by Write.
+const (
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+	FormatTable = "table"
+	FormatCSV   = "csv"
+	FormatTSV   = "tsv"
+	// FormatNDJSON writes one record per line, see PrintPages for streaming.
+	FormatNDJSON = "ndjson"
+	// FormatJSONPath and FormatGoTemplate take the template after a =, e.g. jsonpath={.values[*].accountId}
+	FormatJSONPath   = "jsonpath"
+	FormatGoTemplate = "go-template"
+)
+
+var formats = []string{FormatJSON, FormatYAML, FormatTable, FormatCSV, FormatTSV, FormatNDJSON, FormatJSONPath + "=...", FormatGoTemplate + "=..."}
+
+// Options select the format of the output.
+// Columns and SortBy only apply to row based formats like table and csv.
+// Delimiter and DecimalComma only apply to csv, tsv always uses tabs.
+// Template is the template of the jsonpath and go-template formats.
+type Options struct {
+	Format       string
+	Columns      []string
+	SortBy       string
+	Delimiter    rune
+	DecimalComma bool
+	Template     string
+}
+
+// AddFlags adds the output flags to the command and all its subcommands.
+func AddFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringP("output", "o", FormatJSON, fmt.Sprintf("Output format (%s), %s", strings.Join(formats, ", "), jsonPathSyntax))
+	cmd.PersistentFlags().StringSlice("columns", nil, "Columns to show, either column names or field paths like account.bic")
+	cmd.PersistentFlags().String("sort-by", "", "Column to sort by, prefix with - to sort descending")
+	cmd.PersistentFlags().String("delimiter", ",", "Field delimiter of the csv output, e.g. ; for German spreadsheets")
+	cmd.PersistentFlags().Bool("decimal-comma", false, "Use a comma as decimal separator in the csv and tsv output")
+	cmd.PersistentFlags().String("template-file", "", "File with the template of the jsonpath or go-template output")
+	if err := cmd.RegisterFlagCompletionFunc("output", completeFormats); err != nil {
+		panic(err)
+	}
+}
+
+// completeFormats completes --output, the template formats without a space so the template can follow the =.
+func completeFormats(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if strings.HasPrefix(toComplete, FormatJSONPath+"=") || strings.HasPrefix(toComplete, FormatGoTemplate+"=") {
+		return nil, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+	return []string{FormatJSON, FormatYAML, FormatTable, FormatCSV, FormatTSV, FormatNDJSON, FormatJSONPath + "=", FormatGoTemplate + "="}, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+// OptionsFromFlags reads the options from the flags added with AddFlags.
+func OptionsFromFlags(cmd *cobra.Command) (Options, error) {
+	columns, err := cmd.Flags().GetStringSlice("columns")
+	if err != nil {
+		return Options{}, err
+	}
+	delimiter := []rune(cmd.Flag("delimiter").Value.String())
+	if len(delimiter) != 1 {
+		return Options{}, fmt.Errorf("delimiter must be a single character, got %q", string(delimiter))
+	}
+	decimalComma, err := cmd.Flags().GetBool("decimal-comma")
+	if err != nil {
+		return Options{}, err
+	}
+	format, template, _ := strings.Cut(cmd.Flag("output").Value.String(), "=")
+	if templateFile := cmd.Flag("template-file").Value.String(); templateFile != "" {
+		if template != "" {
+			return Options{}, fmt.Errorf("either set the template with --output or --template-file")
+		}
+		content, err := os.ReadFile(templateFile)
+		if err != nil {
+			return Options{}, err
+		}
+		template = string(content)
+	}
+	return Options{
+		Format:       format,
+		Columns:      columns,
+		SortBy:       cmd.Flag("sort-by").Value.String(),
+		Delimiter:    delimiter[0],
+		DecimalComma: decimalComma,
+		Template:     template,
+	}, nil
+}
+
+// Print writes data to the output of the command in the format selected by the flags.
+func Print(cmd *cobra.Command, data any) error {
+	options, err := OptionsFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	return Write(cmd.OutOrStdout(), data, options)
+}
+
+// Write writes data in the format of the options.
+func Write(w io.Writer, data any, options Options) error {
+	slog.Debug(fmt.Sprintf("Output format: %s", options.Format))
+	switch options.Format {
+	case FormatJSON:
+		out, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(out))
+		return err
+	case FormatYAML:
+		out, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		yaml, err := convert.JSONToYAML(string(out))
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, yaml)
+		return err
+	case FormatNDJSON:
+		return writeNDJSON(w, data)
+	case FormatTable:
+		t, err := newTable(data, options)
+		if err != nil {
+			return err
+		}
+		return t.write(w)
+	case FormatCSV, FormatTSV:
+		if options.Format == FormatTSV {
+			options.Delimiter = '\t'
+		}
+		t, err := newDelimitedTable(data, options)
+		if err != nil {
+			return err
+		}
+		return t.writeDelimited(w, options)
+	case FormatJSONPath:
+		if options.Template == "" {
+			return fmt.Errorf("jsonpath output requires a template, e.g. -o jsonpath={.values[*].accountId}")
+		}
+		return writeJSONPath(w, data, options.Template)
+	case FormatGoTemplate:
+		if options.Template == "" {
+			return fmt.Errorf("go-template output requires a template, e.g. -o go-template={{range .Values}}{{.AccountID}}{{end}}")
+		}
+		return writeGoTemplate(w, data, options.Template)
+	}
+	return fmt.Errorf("unsupported output format %q, supported are %s", options.Format, strings.Join(formats, ", "))
+}
@@ -0,0 +1,240 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// table holds the rows of a response as generic JSON values, so columns can address them by field path.
+type table struct {
+	columns []Column
+	rows    []map[string]any
+}
+
+func newTable(data any, options Options) (*table, error) {
+	columns, err := selectColumns(rowType(reflect.TypeOf(data)), options.Columns)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := rows(data)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &table{columns: columns, rows: rows}
+	if options.SortBy != "" {
+		if err := t.sort(options.SortBy, rowType(reflect.TypeOf(data))); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// rows returns the values of a list response, or the response itself as single row.
+func rows(data any) ([]map[string]any, error) {
+	parsed, err := generic(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if object, ok := parsed.(map[string]any); ok {
+		values, ok := object["values"].([]any)
+		if !ok {
+			return []map[string]any{object}, nil
+		}
+		parsed = values
+	}
+
+	list, _ := parsed.([]any)
+	rows := make([]map[string]any, 0, len(list))
+	for _, value := range list {
+		if row, ok := value.(map[string]any); ok {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// generic returns the data as it is represented in JSON, numbers are kept as json.Number.
+func generic(data any) (any, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var parsed any
+	if err := decoder.Decode(&parsed); err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
+
+// rowType returns the type of a single row of the data, e.g. AccountBalance for AccountBalances.
+func rowType(t reflect.Type) reflect.Type {
+	if t == nil {
+		return nil
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct {
+		if field, ok := t.FieldByName("Values"); ok && field.Type.Kind() == reflect.Slice {
+			t = field.Type
+		}
+	}
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func (t *table) sort(sortBy string, rowType reflect.Type) error {
+	descending := strings.HasPrefix(sortBy, "-")
+	column, err := resolveColumn(rowType, strings.TrimPrefix(sortBy, "-"))
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(t.rows, func(i, j int) bool {
+		a, b := sortValue(column.value(t.rows[i])), sortValue(column.value(t.rows[j]))
+		if descending {
+			a, b = b, a
+		}
+		if an, err := strconv.ParseFloat(a, 64); err == nil {
+			if bn, err := strconv.ParseFloat(b, 64); err == nil {
+				return an < bn
+			}
+		}
+		return a < b
+	})
+	return nil
+}
+
+func (t *table) write(w io.Writer) error {
+	cells := make([][]string, len(t.rows))
+	rightAligned := make([]bool, len(t.columns))
+	widths := make([]int, len(t.columns))
+	for i, column := range t.columns {
+		widths[i] = utf8.RuneCountInString(column.header())
+		rightAligned[i] = len(t.rows) > 0
+	}
+
+	for r, row := range t.rows {
+		cells[r] = make([]string, len(t.columns))
+		for i, column := range t.columns {
+			text, numeric := cell(column.value(row))
+			cells[r][i] = text
+			if text != "" && !numeric {
+				rightAligned[i] = false
+			}
+			widths[i] = max(widths[i], utf8.RuneCountInString(text))
+		}
+	}
+
+	headers := make([]string, len(t.columns))
+	for i, column := range t.columns {
+		headers[i] = column.header()
+	}
+	if err := writeLine(w, headers, widths, rightAligned); err != nil {
+		return err
+	}
+	for _, row := range cells {
+		if err := writeLine(w, row, widths, rightAligned); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeLine(w io.Writer, cells []string, widths []int, rightAligned []bool) error {
+	var line strings.Builder
+	for i, text := range cells {
+		if i > 0 {
+			line.WriteString("  ")
+		}
+		padding := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(text))
+		if rightAligned[i] {
+			line.WriteString(padding + text)
+		} else {
+			line.WriteString(text + padding)
+		}
+	}
+	_, err := fmt.Fprintln(w, strings.TrimRight(line.String(), " "))
+	return err
+}
+
+// lookup returns the value at a dot separated path like account.iban.
+func lookup(row map[string]any, path string) any {
+	var value any = row
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
+
+// cell formats a value for the table, numeric is set for numbers and amounts, which are right aligned.
+func cell(value any) (text string, numeric bool) {
+	switch v := value.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, false
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), false
+	case map[string]any:
+		if amount, ok := formatAmount(v); ok {
+			return amount, true
+		}
+	case []any:
+		texts := make([]string, 0, len(v))
+		for _, element := range v {
+			text, _ := cell(element)
+			texts = append(texts, text)
+		}
+		return strings.Join(texts, ","), false
+	}
+	raw, _ := json.Marshal(value)
+	return string(raw), false
+}
+
+// formatAmount formats a comdirect amount like {"value": "1.5", "unit": "EUR"}.
+// The unit XXX is used for quantities in pieces and left out.
+func formatAmount(object map[string]any) (string, bool) {
+	value, ok := object["value"].(string)
+	if !ok {
+		return "", false
+	}
+	unit, _ := object["unit"].(string)
+	if unit == "" || unit == "XXX" {
+		return value, true
+	}
+	return value + " " + unit, true
+}
+
+// sortValue returns the value used for sorting, amounts are sorted by their value.
+func sortValue(value any) string {
+	if object, ok := value.(map[string]any); ok {
+		if amount, ok := object["value"].(string); ok {
+			return amount
+		}
+	}
+	text, _ := cell(value)
+	return text
+}
@@ -0,0 +1,21 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"text/template"
+)
+
+// writeGoTemplate executes the template on the typed response, so fields are addressed by their Go names like .Values.
+func writeGoTemplate(w io.Writer, data any, text string) error {
+	t, err := template.New("output").Funcs(template.FuncMap{"json": toJSON}).Parse(text)
+	if err != nil {
+		return err
+	}
+	return t.Execute(w, data)
+}
+
+func toJSON(value any) (string, error) {
+	out, err := json.Marshal(value)
+	return string(out), err
+}
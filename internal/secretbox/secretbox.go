@@ -0,0 +1,156 @@
+// Package secretbox encrypts small files like tokens with AES-GCM, the key is derived from a passphrase with scrypt.
+// Derived keys are cached for the life of the process, so a command reading and writing several files derives the key once.
+// The layout is magic | version | salt | nonce | ciphertext, the header is authenticated as well.
+package secretbox
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	formatVersion = 1
+	saltSize      = 16
+	keySize       = 32
+
+	// scrypt parameters as recommended for interactive logins
+	scryptN = 32768
+	scryptR = 8
+	scryptP = 1
+)
+
+var (
+	// ErrUnknownFormat is returned by Open if the data does not start with the magic.
+	ErrUnknownFormat = errors.New("unknown format")
+	// ErrTampered is returned by Open if the data can't be decrypted, because it was changed or the passphrase is wrong.
+	ErrTampered = errors.New("data has been tampered with or the passphrase is wrong")
+)
+
+// derivedKey identifies a key derived by scrypt.
+type derivedKey struct {
+	passphrase string
+	salt       string
+}
+
+// keys caches the derived keys for the life of the process, as scrypt is slow on purpose.
+// sealSalts holds the salt Seal uses per passphrase, so all files sealed by a process share one derivation.
+var keys = struct {
+	sync.Mutex
+	derived   map[derivedKey][]byte
+	sealSalts map[string][]byte
+}{derived: make(map[derivedKey][]byte), sealSalts: make(map[string][]byte)}
+
+// Seal encrypts the plaintext with a key derived from the passphrase and a salt.
+// The salt is random per process, or the salt of the first file opened with the passphrase, so its key is reused.
+func Seal(passphrase string, magic []byte, plaintext []byte) ([]byte, error) {
+	salt, err := sealSalt(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	header := append(append(append([]byte{}, magic...), formatVersion), salt...)
+	data := append(append([]byte{}, header...), nonce...)
+	return aead.Seal(data, nonce, plaintext, header), nil
+}
+
+// Open decrypts data written by Seal with the same passphrase and magic.
+func Open(passphrase string, magic []byte, data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, magic) {
+		return nil, ErrUnknownFormat
+	}
+	headerSize := len(magic) + 1 + saltSize
+	if len(data) < headerSize {
+		return nil, ErrTampered
+	}
+	if version := data[len(magic)]; version != formatVersion {
+		return nil, fmt.Errorf("unsupported format version %d", version)
+	}
+
+	header := data[:headerSize]
+	aead, err := newAEAD(passphrase, header[len(magic)+1:])
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < headerSize+aead.NonceSize() {
+		return nil, ErrTampered
+	}
+	nonce := data[headerSize : headerSize+aead.NonceSize()]
+	plaintext, err := aead.Open(nil, nonce, data[headerSize+aead.NonceSize():], header)
+	if err != nil {
+		return nil, ErrTampered
+	}
+	adoptSealSalt(passphrase, header[len(magic)+1:])
+	return plaintext, nil
+}
+
+// sealSalt returns the salt Seal uses for the passphrase, a random one is created on first use.
+func sealSalt(passphrase string) ([]byte, error) {
+	keys.Lock()
+	defer keys.Unlock()
+	if salt, ok := keys.sealSalts[passphrase]; ok {
+		return salt, nil
+	}
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	keys.sealSalts[passphrase] = salt
+	return salt, nil
+}
+
+// adoptSealSalt makes Seal use the salt of a successfully opened file, if it has not sealed anything with the passphrase yet.
+func adoptSealSalt(passphrase string, salt []byte) {
+	keys.Lock()
+	defer keys.Unlock()
+	if _, ok := keys.sealSalts[passphrase]; !ok {
+		keys.sealSalts[passphrase] = bytes.Clone(salt)
+	}
+}
+
+// deriveKey derives the AES key from the passphrase with scrypt, keys are derived once per process.
+func deriveKey(passphrase string, salt []byte) ([]byte, error) {
+	id := derivedKey{passphrase: passphrase, salt: string(salt)}
+	keys.Lock()
+	key, ok := keys.derived[id]
+	keys.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keySize)
+	if err != nil {
+		return nil, err
+	}
+	keys.Lock()
+	keys.derived[id] = key
+	keys.Unlock()
+	return key, nil
+}
+
+// newAEAD returns AES-GCM with the key derived from the passphrase and salt.
+func newAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,112 @@
+package secretbox
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+var testMagic = []byte("TEST")
+
+func TestSealOpen(t *testing.T) {
+	sealed, err := Seal("passphrase", testMagic, []byte("secret"))
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	plaintext, err := Open("passphrase", testMagic, sealed)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if string(plaintext) != "secret" {
+		t.Errorf("got %q, want %q", plaintext, "secret")
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	sealed, err := Seal("passphrase", testMagic, []byte("secret"))
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	tampered := append([]byte{}, sealed...)
+	tampered[len(tampered)-1] ^= 0xff
+
+	tests := []struct {
+		name       string
+		passphrase string
+		data       []byte
+		want       error
+	}{
+		{"wrong passphrase", "other", sealed, ErrTampered},
+		{"tampered", "passphrase", tampered, ErrTampered},
+		{"truncated", "passphrase", sealed[:len(testMagic)+4], ErrTampered},
+		{"unknown magic", "passphrase", append([]byte("NOPE"), sealed[len(testMagic):]...), ErrUnknownFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Open(tt.passphrase, testMagic, tt.data); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// resetKeys forgets the derived keys and seal salts of earlier tests.
+func resetKeys() {
+	keys.Lock()
+	defer keys.Unlock()
+	keys.derived = make(map[derivedKey][]byte)
+	keys.sealSalts = make(map[string][]byte)
+}
+
+// salt returns the salt of sealed data.
+func salt(data []byte) []byte {
+	return data[len(testMagic)+1 : len(testMagic)+1+saltSize]
+}
+
+func TestSealReusesSaltOfOpenedData(t *testing.T) {
+	resetKeys()
+	written, err := Seal("passphrase", testMagic, []byte("token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a new process opens the file and writes another one, only one key is derived
+	resetKeys()
+	if _, err := Open("passphrase", testMagic, written); err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := Seal("passphrase", testMagic, []byte("index"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(salt(sealed), salt(written)) {
+		t.Error("Seal did not reuse the salt of the opened data")
+	}
+	if len(keys.derived) != 1 {
+		t.Errorf("derived %d keys, want 1", len(keys.derived))
+	}
+}
+
+func BenchmarkOpen(b *testing.B) {
+	sealed, err := Seal("passphrase", testMagic, []byte("secret"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("derived", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			resetKeys()
+			if _, err := Open("passphrase", testMagic, sealed); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := Open("passphrase", testMagic, sealed); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
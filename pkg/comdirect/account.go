@@ -1,9 +1,10 @@
 package comdirect
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 const (
@@ -13,6 +14,8 @@ const (
 
 type AccountBalancesOptions struct {
 	ExludeAccount bool
+	PagingFirst   int
+	PagingCount   int
 }
 
 func (o *AccountBalancesOptions) queryParams() []string {
@@ -20,32 +23,31 @@ func (o *AccountBalancesOptions) queryParams() []string {
 	if o.ExludeAccount {
 		queryParams = append(queryParams, fmt.Sprintf("%s=%s", excludeProperty, "account"))
 	}
+	queryParams = append(queryParams, pagingQueryParams(o.PagingFirst, o.PagingCount)...)
 	return queryParams
 }
 
 // AccountBalances returns the balances of all accounts of the user.
 // For more information see https://www.comdirect.de/cms/media/comdirect_REST_API_Dokumentation.pdf
 func (c *Client) AccountBalances(token *AuthToken, options *AccountBalancesOptions) (*AccountBalances, error) {
+	return c.accountBalances(context.Background(), token, options)
+}
+
+func (c *Client) accountBalances(ctx context.Context, token *AuthToken, options *AccountBalancesOptions) (*AccountBalances, error) {
 	url := fmt.Sprintf("%s/banking/clients/user/v2/accounts/balances", c.config.APIURL)
 	if options != nil {
 		url = addQueryParams(url, options)
 	}
 
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	addXHTTPRequestInfoHeader(req, token.SessionGUID, token.RequestID)
 	req.Header.Add("Accept", "application/json")
 
-	resBody, _, err := c.authenticatedRequest(req, token, http.StatusOK)
-	if err != nil {
-		return nil, err
-	}
-
 	var accountBalances AccountBalances
-	if err := json.NewDecoder(resBody).Decode(&accountBalances); err != nil {
+	if _, err := c.authenticatedRequest(req, token, http.StatusOK, &accountBalances); err != nil {
 		return nil, err
 	}
 
@@ -55,23 +57,21 @@ func (c *Client) AccountBalances(token *AuthToken, options *AccountBalancesOptio
 // AccountBalance returns the balance of a specific account.
 // For more information see https://www.comdirect.de/cms/media/comdirect_REST_API_Dokumentation.pdf
 func (c *Client) AccountBalance(token *AuthToken, accountID string) (*AccountBalance, error) {
+	return c.accountBalance(context.Background(), token, accountID)
+}
+
+func (c *Client) accountBalance(ctx context.Context, token *AuthToken, accountID string) (*AccountBalance, error) {
 	url := fmt.Sprintf("%s/banking/v2/accounts/%s/balances", c.config.APIURL, accountID)
 
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	addXHTTPRequestInfoHeader(req, token.SessionGUID, token.RequestID)
 	req.Header.Add("Accept", "application/json")
 
-	resBody, _, err := c.authenticatedRequest(req, token, http.StatusOK)
-	if err != nil {
-		return nil, err
-	}
-
 	var accountBalance AccountBalance
-	if err := json.NewDecoder(resBody).Decode(&accountBalance); err != nil {
+	if _, err := c.authenticatedRequest(req, token, http.StatusOK, &accountBalance); err != nil {
 		return nil, err
 	}
 
@@ -89,7 +89,10 @@ const (
 type AccountTransactionOptions struct {
 	IncludeAccount   bool
 	PagingFirst      int
+	PagingCount      int
 	TransactionState TransactionState
+	MinBookingDate   time.Time
+	MaxBookingDate   time.Time
 }
 
 func (o *AccountTransactionOptions) queryParams() []string {
@@ -97,8 +100,12 @@ func (o *AccountTransactionOptions) queryParams() []string {
 	if o.IncludeAccount {
 		queryParams = append(queryParams, fmt.Sprintf("%s=%s", includeProperty, "account"))
 	}
-	if o.PagingFirst > 0 {
-		queryParams = append(queryParams, fmt.Sprintf("paging-first=%d", o.PagingFirst))
+	queryParams = append(queryParams, pagingQueryParams(o.PagingFirst, o.PagingCount)...)
+	if !o.MinBookingDate.IsZero() {
+		queryParams = append(queryParams, fmt.Sprintf("min-bookingDate=%s", o.MinBookingDate.Format("2006-01-02")))
+	}
+	if !o.MaxBookingDate.IsZero() {
+		queryParams = append(queryParams, fmt.Sprintf("max-bookingDate=%s", o.MaxBookingDate.Format("2006-01-02")))
 	}
 	if o.TransactionState != "" {
 		queryParams = append(queryParams, fmt.Sprintf("transactionState=%s", o.TransactionState))
@@ -111,27 +118,25 @@ func (o *AccountTransactionOptions) queryParams() []string {
 // AccountTransactions returns the transactions of a specific account.
 // For more information see https://www.comdirect.de/cms/media/comdirect_REST_API_Dokumentation.pdf
 func (c *Client) AccountTransactions(token *AuthToken, accountID string, options *AccountTransactionOptions) (*AccountTransactions, error) {
+	return c.accountTransactions(context.Background(), token, accountID, options)
+}
+
+func (c *Client) accountTransactions(ctx context.Context, token *AuthToken, accountID string, options *AccountTransactionOptions) (*AccountTransactions, error) {
 	url := fmt.Sprintf("%s/banking/v1/accounts/%s/transactions", c.config.APIURL, accountID)
 
 	if options != nil {
-		addQueryParams(url, options)
+		url = addQueryParams(url, options)
 	}
 
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	addXHTTPRequestInfoHeader(req, token.SessionGUID, token.RequestID)
 	req.Header.Add("Accept", "application/json")
 
-	resBody, _, err := c.authenticatedRequest(req, token, http.StatusOK)
-	if err != nil {
-		return nil, err
-	}
-
 	var accountTransactions AccountTransactions
-	if err := json.NewDecoder(resBody).Decode(&accountTransactions); err != nil {
+	if _, err := c.authenticatedRequest(req, token, http.StatusOK, &accountTransactions); err != nil {
 		return nil, err
 	}
 
@@ -139,42 +144,27 @@ func (c *Client) AccountTransactions(token *AuthToken, accountID string, options
 }
 
 func (c *Client) PaginatedAccountTransactions(token *AuthToken, accountID string, amount int, options *AccountTransactionOptions) (*AccountTransactions, error) {
+	return c.paginatedAccountTransactions(context.Background(), staticToken(token), accountID, amount, options)
+}
+
+func (c *Client) paginatedAccountTransactions(ctx context.Context, token tokenFunc, accountID string, amount int, options *AccountTransactionOptions) (*AccountTransactions, error) {
 	if options == nil {
 		options = &AccountTransactionOptions{}
 	}
 	options.TransactionState = TransactionStateBooked
-	firstPage, err := c.AccountTransactions(token, accountID, options)
+	first, values, err := collect(c.accountTransactionPages(ctx, token, accountID, options), amount, func(page *AccountTransactions) []AccountTransaction {
+		return page.Values
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	transactions := []*AccountTransactions{firstPage}
-	for len(transactions) < amount/globalPageSize {
-		options.PagingFirst = len(transactions) * globalPageSize
-		page, err := c.AccountTransactions(token, accountID, options)
-		if err != nil {
-			return nil, err
-		}
-		if len(page.Values) == 0 {
-			break
-		}
-		transactions = append(transactions, page)
-	}
-
 	return &AccountTransactions{
 		Paging: Paging{
-			Index:   0,
-			Matches: len(transactions) * globalPageSize,
+			Index:   first.Paging.Index,
+			Matches: first.Paging.Matches,
 		},
-		AggregatedTransactions: firstPage.AggregatedTransactions,
-		Values:                 flattenTransactions(transactions),
+		AggregatedTransactions: first.AggregatedTransactions,
+		Values:                 values,
 	}, nil
 }
-
-func flattenTransactions(transactions []*AccountTransactions) []AccountTransaction {
-	var flattened []AccountTransaction
-	for _, page := range transactions {
-		flattened = append(flattened, page.Values...)
-	}
-	return flattened
-}
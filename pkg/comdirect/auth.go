@@ -1,13 +1,12 @@
 package comdirect
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log/slog"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/google/uuid"
 )
@@ -18,12 +17,16 @@ import (
 // If the challenge is handled correctly, the token is returned.
 // For more information see https://www.comdirect.de/cms/media/comdirect_REST_API_Dokumentation.pdf
 func (c *Client) Authenticate(twoFaHandler func(tanHeader TANHeader) error) (*AuthToken, error) {
-	token, err := c.newInitialToken()
+	return c.authenticate(context.Background(), twoFaHandler)
+}
+
+func (c *Client) authenticate(ctx context.Context, twoFaHandler func(tanHeader TANHeader) error) (*AuthToken, error) {
+	token, err := c.newInitialToken(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	sessions, err := c.sessions(token)
+	sessions, err := c.sessions(ctx, token)
 	if err != nil {
 		return nil, err
 	}
@@ -36,7 +39,7 @@ func (c *Client) Authenticate(twoFaHandler func(tanHeader TANHeader) error) (*Au
 	guessedSession := sessions[0]
 
 	sessionGUID := token.SessionGUID
-	challengeID, err := c.validateSession(token, guessedSession.Identifier)
+	challengeID, err := c.validateSession(ctx, token, guessedSession.Identifier)
 	if err != nil {
 		return nil, err
 	}
@@ -46,17 +49,19 @@ func (c *Client) Authenticate(twoFaHandler func(tanHeader TANHeader) error) (*Au
 		return nil, err
 	}
 
-	_, err = c.activateSession(token, sessionGUID, challengeID.Id)
+	_, err = c.activateSession(ctx, token, sessionGUID, challengeID.Id)
 	if err != nil {
 		return nil, err
 	}
 
-	secondaryToken, err := c.newSecondaryToken(token)
+	secondaryToken, err := c.newSecondaryToken(ctx, token)
 	if err != nil {
 		return nil, err
 	}
+	secondaryToken.TANType = challengeID.Typ
 
-	c.tokenStore[secondaryToken.SessionGUID] = secondaryToken
+	c.trackToken(secondaryToken)
+	c.persistToken(secondaryToken)
 
 	return secondaryToken, nil
 }
@@ -67,10 +72,21 @@ func (c *Client) Authenticate(twoFaHandler func(tanHeader TANHeader) error) (*Au
 // For more information see https://www.comdirect.de/cms/media/comdirect_REST_API_Dokumentation.pdf
 // If a token is used in a current request, the token is locked and cannot be refreshed, a LockedTokenError is returned. In this case try again.
 func (c *Client) RefreshToken(token *AuthToken) (*AuthToken, error) {
-	slog.Debug("Refreshing token")
+	newToken, err := c.refreshToken(context.Background(), token)
+	if err != nil {
+		return nil, err
+	}
+	c.trackToken(newToken)
+	return newToken, nil
+}
+
+// refreshToken requests a new token with the refresh token, the caller decides which token is tracked.
+
+func (c *Client) refreshToken(ctx context.Context, token *AuthToken) (*AuthToken, error) {
+	c.logger.Debug("Refreshing token")
 	payload := fmt.Sprintf("client_id=%s&client_secret=%s&grant_type=refresh_token&refresh_token=%s", c.config.ClientID, c.config.ClientSecret, token.RefreshToken)
 	body := strings.NewReader(payload)
-	req, err := http.NewRequest(http.MethodPost, c.config.TokenURL, body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.TokenURL, body)
 	if err != nil {
 		return nil, err
 	}
@@ -78,11 +94,11 @@ func (c *Client) RefreshToken(token *AuthToken) (*AuthToken, error) {
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 	req.Header.Add("Accept", "application/json")
 
-	creationTime := time.Now()
+	creationTime := c.clock.Now()
 	if token.IsLocked() {
 		return nil, errors.New(LockedTokenError)
 	}
-	res, err := c.client.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -90,7 +106,7 @@ func (c *Client) RefreshToken(token *AuthToken) (*AuthToken, error) {
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
-		return nil, handleRequestError(res)
+		return nil, c.handleRequestError(req, res)
 	}
 
 	var authResponse authResponse
@@ -104,46 +120,59 @@ func (c *Client) RefreshToken(token *AuthToken) (*AuthToken, error) {
 	}
 
 	newToken := &AuthToken{
-		AccessToken:  authResponse.AccessToken,
-		ExpiresIn:    authResponse.ExpiresIn,
-		RefreshToken: authResponse.RefreshToken,
-		CreationTime: creationTime,
-		Scope:        authResponse.Scope,
-		SessionGUID:  token.SessionGUID,
-		RequestID:    token.RequestID,
-	}
-
-	c.tokenStore[newToken.SessionGUID] = newToken
+		AccessToken:      authResponse.AccessToken,
+		ExpiresIn:        authResponse.ExpiresIn,
+		RefreshToken:     authResponse.RefreshToken,
+		RefreshExpiresIn: authResponse.RefreshExpiresIn,
+		CreationTime:     creationTime,
+		Scope:            authResponse.Scope,
+		SessionGUID:      token.SessionGUID,
+		KDNR:             authResponse.KDNR,
+		BPID:             authResponse.BPID,
+		KontaktID:        authResponse.KontaktId,
+	}
+	newToken.inheritSessionInfo(token)
+
+	c.persistToken(newToken)
 	return newToken, nil
 }
 
 // RevokeToken revokes the token.
 // For more information see https://www.comdirect.de/cms/media/comdirect_REST_API_Dokumentation.pdf
 func (c *Client) RevokeToken(token *AuthToken) error {
-	slog.Debug("Revoking token")
-	req, err := http.NewRequest(http.MethodDelete, c.config.RevokeTokenURL, nil)
+	return c.revokeToken(context.Background(), token)
+}
+
+func (c *Client) revokeToken(ctx context.Context, token *AuthToken) error {
+	c.logger.Debug("Revoking token")
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.config.RevokeTokenURL, nil)
 	if err != nil {
 		return err
 	}
 
 	req.Header.Add("Accept", "application/json")
 
-	_, _, err = c.authenticatedRequest(req, token, http.StatusNoContent)
+	_, err = c.authenticatedRequest(req, token, http.StatusNoContent, nil)
 	if err != nil {
 		return err
 	}
 
-	delete(c.tokenStore, token.SessionGUID)
+	c.untrackToken(token.SessionGUID)
+	if c.store != nil {
+		if err := c.store.Delete(c.storeKey); err != nil {
+			c.logger.Warn(fmt.Sprintf("Unable to delete token from store: %s", err))
+		}
+	}
 	return nil
 }
 
-func (c *Client) newInitialToken() (*AuthToken, error) {
-	slog.Debug("Getting token")
+func (c *Client) newInitialToken(ctx context.Context) (*AuthToken, error) {
+	c.logger.Debug("Getting token")
 	sessionID := uuid.New().String()
 	payload := fmt.Sprintf("client_id=%s&client_secret=%s&grant_type=password&username=%s&password=%s", c.config.ClientID, c.config.ClientSecret, c.config.Zugangsnummer, c.config.Pin)
 	body := strings.NewReader(payload)
 
-	req, err := http.NewRequest(http.MethodPost, c.config.TokenURL, body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.TokenURL, body)
 
 	if err != nil {
 		return nil, err
@@ -152,8 +181,8 @@ func (c *Client) newInitialToken() (*AuthToken, error) {
 	req.Header.Add("Accept", "application/json")
 	req.Header.Add("Cookie", fmt.Sprintf("qSession=%s", sessionID))
 
-	creationTime := time.Now()
-	res, err := c.client.Do(req)
+	creationTime := c.clock.Now()
+	res, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -161,7 +190,7 @@ func (c *Client) newInitialToken() (*AuthToken, error) {
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
-		return nil, handleRequestError(res)
+		return nil, c.handleRequestError(req, res)
 	}
 
 	var authResponse authResponse
@@ -170,44 +199,40 @@ func (c *Client) newInitialToken() (*AuthToken, error) {
 	}
 
 	return &AuthToken{
-		AccessToken:  authResponse.AccessToken,
-		ExpiresIn:    authResponse.ExpiresIn,
-		RefreshToken: authResponse.RefreshToken,
-		CreationTime: creationTime,
-		Scope:        authResponse.Scope,
-		SessionGUID:  sessionID,
-		RequestID:    requestID(),
+		AccessToken:      authResponse.AccessToken,
+		ExpiresIn:        authResponse.ExpiresIn,
+		RefreshToken:     authResponse.RefreshToken,
+		RefreshExpiresIn: authResponse.RefreshExpiresIn,
+		CreationTime:     creationTime,
+		Scope:            authResponse.Scope,
+		SessionGUID:      sessionID,
+		KDNR:             authResponse.KDNR,
+		BPID:             authResponse.BPID,
+		KontaktID:        authResponse.KontaktId,
 	}, nil
 }
 
-func (c *Client) sessions(token *AuthToken) ([]session, error) {
-	slog.Debug("Checking session status")
-	c.ensureValidToken(token)
+func (c *Client) sessions(ctx context.Context, token *AuthToken) ([]session, error) {
+	c.logger.Debug("Checking session status")
 	url := fmt.Sprintf("%s/session/clients/user/v1/sessions", c.config.APIURL)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	addXHTTPRequestInfoHeader(req, token.SessionGUID, token.RequestID)
 	req.Header.Add("Accept", "application/json")
 	req.Header.Add("Cookie", fmt.Sprintf("qSession=%s", token.SessionGUID))
 
-	resBody, _, err := c.authenticatedRequest(req, token, http.StatusOK)
-	if err != nil {
-		return nil, err
-	}
-
 	var sessions []session
-	if err := json.NewDecoder(resBody).Decode(&sessions); err != nil {
+	if _, err := c.authenticatedRequest(req, token, http.StatusOK, &sessions); err != nil {
 		return nil, err
 	}
 
 	return sessions, nil
 }
 
-func (c *Client) validateSession(token *AuthToken, sessionID string) (*TANHeader, error) {
-	slog.Debug("Validating session")
+func (c *Client) validateSession(ctx context.Context, token *AuthToken, sessionID string) (*TANHeader, error) {
+	c.logger.Debug("Validating session")
 	currentSession := session{Identifier: sessionID}
 	currentSession.Activated2FA = true
 	currentSession.SessionTanActive = true
@@ -218,22 +243,17 @@ func (c *Client) validateSession(token *AuthToken, sessionID string) (*TANHeader
 		return nil, err
 	}
 	body := strings.NewReader(string(payload))
-	req, err := http.NewRequest(http.MethodPost, url, body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
 	if err != nil {
 		return nil, err
 	}
 
-	addXHTTPRequestInfoHeader(req, token.SessionGUID, token.RequestID)
 	req.Header.Add("Accept", "application/json")
 	req.Header.Add("Content-Type", "application/json")
 
-	resBody, header, err := c.authenticatedRequest(req, token, http.StatusCreated)
-	if err != nil {
-		return nil, err
-	}
-
 	var session session
-	if err := json.NewDecoder(resBody).Decode(&session); err != nil {
+	header, err := c.authenticatedRequest(req, token, http.StatusCreated, &session)
+	if err != nil {
 		return nil, err
 	}
 
@@ -257,8 +277,8 @@ func (c *Client) validateSession(token *AuthToken, sessionID string) (*TANHeader
 	return nil, fmt.Errorf("session tan not active")
 }
 
-func (c *Client) activateSession(token *AuthToken, sessionID string, challengeId string) (*session, error) {
-	slog.Debug("Activating session")
+func (c *Client) activateSession(ctx context.Context, token *AuthToken, sessionID string, challengeId string) (*session, error) {
+	c.logger.Debug("Activating session")
 	currentSession := session{Identifier: sessionID}
 	currentSession.Activated2FA = true
 	currentSession.SessionTanActive = true
@@ -269,24 +289,18 @@ func (c *Client) activateSession(token *AuthToken, sessionID string, challengeId
 		return nil, err
 	}
 	body := strings.NewReader(string(payload))
-	req, err := http.NewRequest(http.MethodPatch, url, body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, url, body)
 	if err != nil {
 		return nil, err
 	}
 
-	addXHTTPRequestInfoHeader(req, token.SessionGUID, token.RequestID)
 	addXOnceAuthenticationInfoHeader(req, challengeId)
 	addXOnceAuthenticationHeader(req, "000000")
 	req.Header.Add("Accept", "application/json")
 	req.Header.Add("Content-Type", "application/json")
 
-	resBody, _, err := c.authenticatedRequest(req, token, http.StatusOK)
-	if err != nil {
-		return nil, err
-	}
-
 	var session session
-	if err := json.NewDecoder(resBody).Decode(&session); err != nil {
+	if _, err := c.authenticatedRequest(req, token, http.StatusOK, &session); err != nil {
 		return nil, err
 	}
 	if !session.SessionTanActive {
@@ -296,13 +310,13 @@ func (c *Client) activateSession(token *AuthToken, sessionID string, challengeId
 	return &session, nil
 }
 
-func (c *Client) newSecondaryToken(token *AuthToken) (*AuthToken, error) {
-	slog.Debug("Getting secondary token")
+func (c *Client) newSecondaryToken(ctx context.Context, token *AuthToken) (*AuthToken, error) {
+	c.logger.Debug("Getting secondary token")
 
 	payload := fmt.Sprintf("client_id=%s&client_secret=%s&grant_type=cd_secondary&token=%s", c.config.ClientID, c.config.ClientSecret, token.AccessToken)
 	body := strings.NewReader(payload)
 
-	req, err := http.NewRequest(http.MethodPost, c.config.TokenURL, body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.TokenURL, body)
 	if err != nil {
 		return nil, err
 	}
@@ -310,13 +324,13 @@ func (c *Client) newSecondaryToken(token *AuthToken) (*AuthToken, error) {
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 	req.Header.Add("Accept", "application/json")
 
-	res, err := c.client.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
 
 	if res.StatusCode != http.StatusOK {
-		return nil, handleRequestError(res)
+		return nil, c.handleRequestError(req, res)
 	}
 
 	defer res.Body.Close()
@@ -326,26 +340,38 @@ func (c *Client) newSecondaryToken(token *AuthToken) (*AuthToken, error) {
 		return nil, err
 	}
 
-	return &AuthToken{
-		AccessToken:  authResponse.AccessToken,
-		ExpiresIn:    authResponse.ExpiresIn,
-		RefreshToken: authResponse.RefreshToken,
-		Scope:        authResponse.Scope,
-		CreationTime: time.Now(),
-		SessionGUID:  token.SessionGUID,
-		RequestID:    token.RequestID,
-	}, nil
+	secondaryToken := &AuthToken{
+		AccessToken:      authResponse.AccessToken,
+		ExpiresIn:        authResponse.ExpiresIn,
+		RefreshToken:     authResponse.RefreshToken,
+		RefreshExpiresIn: authResponse.RefreshExpiresIn,
+		Scope:            authResponse.Scope,
+		CreationTime:     c.clock.Now(),
+		SessionGUID:      token.SessionGUID,
+		KDNR:             authResponse.KDNR,
+		BPID:             authResponse.BPID,
+		KontaktID:        authResponse.KontaktId,
+	}
+	secondaryToken.inheritSessionInfo(token)
+
+	return secondaryToken, nil
 }
 
-func (c *Client) ensureValidToken(token *AuthToken) error {
-	if token.IsExpired() {
-		slog.Debug("Token expired, refreshing")
-		newToken, err := c.RefreshToken(token)
-		if err != nil {
-			slog.Debug("Token refresh failed")
-			return err
-		}
-		token = newToken
+// ensureValidToken refreshes an expired token in place, see replaceToken.
+func (c *Client) ensureValidToken(ctx context.Context, token *AuthToken) error {
+	c.tokensMu.Lock()
+	expired := token.willExpireAt(c.clock.Now(), 0)
+	c.tokensMu.Unlock()
+	if !expired {
+		return nil
+	}
+
+	c.logger.Debug("Token expired, refreshing")
+	newToken, err := c.refreshToken(ctx, token)
+	if err != nil {
+		c.logger.Debug("Token refresh failed")
+		return err
 	}
+	c.replaceToken(token, newToken)
 	return nil
 }
@@ -0,0 +1,76 @@
+package comdirect
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestEnsureValidTokenUpdatesCallerToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth/token" {
+			if err := r.ParseForm(); err != nil || r.PostForm.Get("refresh_token") != "old-refresh" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"access_token":"new-access","refresh_token":"new-refresh","expires_in":599}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer new-access" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{APIURL: server.URL + "/api", TokenURL: server.URL + "/oauth/token"})
+	token := &AuthToken{
+		AccessToken:  "old-access",
+		RefreshToken: "old-refresh",
+		ExpiresIn:    599,
+		CreationTime: time.Now().Add(-time.Hour),
+		SessionGUID:  "session",
+		KDNR:         "kdnr",
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL+"/api/test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.authenticatedRequest(req, token, http.StatusOK, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if token.AccessToken != "new-access" || token.RefreshToken != "new-refresh" {
+		t.Errorf("caller token was not refreshed: %q %q", token.AccessToken, token.RefreshToken)
+	}
+	if token.KDNR != "kdnr" || token.SessionGUID != "session" {
+		t.Errorf("session information was lost: %q %q", token.KDNR, token.SessionGUID)
+	}
+	if !slices.Contains(client.trackedTokens(), token) {
+		t.Error("caller token is not the active token of the session")
+	}
+}
+
+func TestRefreshExpiresAt(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		refreshExpiresIn int
+		want             time.Time
+	}{
+		"documented lifetime": {0, created.Add(20 * time.Minute)},
+		"sent lifetime":       {3600, created.Add(time.Hour)},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			token := &AuthToken{CreationTime: created, RefreshExpiresIn: tt.refreshExpiresIn}
+			if got := token.RefreshExpiresAt(); !got.Equal(tt.want) {
+				t.Errorf("RefreshExpiresAt() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"sync"
 	"time"
 )
 
@@ -22,13 +23,47 @@ type Config struct {
 type Client struct {
 	config                Config
 	client                *http.Client
-	tokenStore            map[string]*AuthToken
+	transport             http.RoundTripper
 	requestMonitor        map[time.Time]int
 	requestLimitPerSecond int
+	userAgent             string
+	retryPolicy           RetryPolicy
+	clock                 Clock
+	logger                *slog.Logger
+	unredactedLogs        bool
+	store                 TokenStore
+	storeKey              string
+
+	// tokensMu guards activeTokens and the tokens replaced by a refresh, see replaceToken
+	tokensMu     sync.Mutex
+	activeTokens map[string]*AuthToken
 }
 
-func NewClient(config Config) *Client {
-	return &Client{config: config, client: &http.Client{}, tokenStore: make(map[string]*AuthToken), requestMonitor: make(map[time.Time]int), requestLimitPerSecond: requestLimitPerSecond}
+// NewClient creates a new client for the given config.
+// The behaviour of the client can be adjusted with options, e.g. WithHTTPClient or WithLogger.
+func NewClient(config Config, opts ...Option) *Client {
+	c := &Client{
+		config:                config,
+		client:                &http.Client{},
+		activeTokens:          make(map[string]*AuthToken),
+		requestMonitor:        make(map[time.Time]int),
+		requestLimitPerSecond: requestLimitPerSecond,
+		clock:                 systemClock{},
+		logger:                slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if recorder, ok := c.transport.(*Recorder); ok && recorder.logger == nil {
+		recorder.logger = c.logger
+	}
+	if c.transport != nil {
+		// a shallow copy, so a shared client like http.DefaultClient is not modified
+		client := *c.client
+		client.Transport = c.transport
+		c.client = &client
+	}
+	return c
 }
 
 // AutoRefreshToken checks if any token is about to expire and refreshes it
@@ -44,8 +79,8 @@ func (c *Client) AutoRefreshToken(ctx context.Context, expirationThreshold time.
 			return
 		default:
 			{
-				for _, token := range c.tokenStore {
-					if token.WillExpireIn(expirationThreshold) {
+				for _, token := range c.trackedTokens() {
+					if token.willExpireAt(c.clock.Now(), expirationThreshold) {
 						expiringTokens <- token
 					}
 				}
@@ -61,17 +96,51 @@ func (c *Client) tokenRefresher(ctx context.Context, expiringTokens chan *AuthTo
 			return
 		case token := <-expiringTokens:
 			{
-				refreshedToken, err := c.RefreshToken(token)
+				refreshedToken, err := c.refreshToken(ctx, token)
 				if err != nil {
 					if err.Error() == LockedTokenError {
-						slog.Warn("Token is locked, skipping refresh")
+						c.logger.Warn("Token is locked, skipping refresh")
 					} else {
-						slog.Error(err.Error())
+						c.logger.Error(err.Error())
 					}
 					continue
 				}
-				token = refreshedToken
+				c.replaceToken(token, refreshedToken)
 			}
 		}
 	}
 }
+
+// trackToken adds the token to the active tokens, which are refreshed by AutoRefreshToken.
+func (c *Client) trackToken(token *AuthToken) {
+	c.tokensMu.Lock()
+	defer c.tokensMu.Unlock()
+	c.activeTokens[token.SessionGUID] = token
+}
+
+// untrackToken removes the token of the session from the active tokens.
+func (c *Client) untrackToken(sessionGUID string) {
+	c.tokensMu.Lock()
+	defer c.tokensMu.Unlock()
+	delete(c.activeTokens, sessionGUID)
+}
+
+// trackedTokens returns a snapshot of the active tokens.
+func (c *Client) trackedTokens() []*AuthToken {
+	c.tokensMu.Lock()
+	defer c.tokensMu.Unlock()
+	tokens := make([]*AuthToken, 0, len(c.activeTokens))
+	for _, token := range c.activeTokens {
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// replaceToken writes the refreshed token into the token of the caller, so every reference to it sees the new refresh token.
+// The caller's token stays the active token of the session.
+func (c *Client) replaceToken(token *AuthToken, refreshed *AuthToken) {
+	c.tokensMu.Lock()
+	defer c.tokensMu.Unlock()
+	token.update(refreshed)
+	c.activeTokens[token.SessionGUID] = token
+}
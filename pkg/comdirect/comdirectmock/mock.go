@@ -0,0 +1,130 @@
+// Package comdirectmock provides a hand-written mock of the comdirect client interfaces.
+// Set the function field of each method used in a test, calling an unset method panics.
+package comdirectmock
+
+import (
+	"io"
+	"iter"
+
+	"github.com/fbufler/comdirect/pkg/comdirect"
+)
+
+type Client struct {
+	AuthenticateFunc                 func(twoFaHandler func(tanHeader comdirect.TANHeader) error) (*comdirect.AuthToken, error)
+	RefreshTokenFunc                 func(token *comdirect.AuthToken) (*comdirect.AuthToken, error)
+	RevokeTokenFunc                  func(token *comdirect.AuthToken) error
+	AccountBalancesFunc              func(token *comdirect.AuthToken, options *comdirect.AccountBalancesOptions) (*comdirect.AccountBalances, error)
+	AccountBalancePagesFunc          func(token *comdirect.AuthToken, options *comdirect.AccountBalancesOptions) iter.Seq2[*comdirect.AccountBalances, error]
+	AccountBalanceFunc               func(token *comdirect.AuthToken, accountID string) (*comdirect.AccountBalance, error)
+	AccountTransactionsFunc          func(token *comdirect.AuthToken, accountID string, options *comdirect.AccountTransactionOptions) (*comdirect.AccountTransactions, error)
+	PaginatedAccountTransactionsFunc func(token *comdirect.AuthToken, accountID string, amount int, options *comdirect.AccountTransactionOptions) (*comdirect.AccountTransactions, error)
+	AccountTransactionPagesFunc      func(token *comdirect.AuthToken, accountID string, options *comdirect.AccountTransactionOptions) iter.Seq2[*comdirect.AccountTransactions, error]
+	DepotsFunc                       func(authToken *comdirect.AuthToken, options *comdirect.DepotsOptions) (*comdirect.Depots, error)
+	PaginatedDepotsFunc              func(authToken *comdirect.AuthToken, amount int) (*comdirect.Depots, error)
+	DepotPagesFunc                   func(authToken *comdirect.AuthToken, options *comdirect.DepotsOptions) iter.Seq2[*comdirect.Depots, error]
+	DepotPositionsFunc               func(authToken *comdirect.AuthToken, depotID string, options *comdirect.DepotPosistionsOptions) (*comdirect.DepotPositions, error)
+	PaginatedDepotPositionsFunc      func(authToken *comdirect.AuthToken, depotID string, amount int, options *comdirect.DepotPosistionsOptions) (*comdirect.DepotPositions, error)
+	DepotPositionPagesFunc           func(authToken *comdirect.AuthToken, depotID string, options *comdirect.DepotPosistionsOptions) iter.Seq2[*comdirect.DepotPositions, error]
+	DepotPositionFunc                func(authToken *comdirect.AuthToken, depotID string, positionID string, options *comdirect.DepotPositionOptions) (*comdirect.DepotPosition, error)
+	DepotTransactionsFunc            func(authToken *comdirect.AuthToken, depotID string, options *comdirect.DepotTransactionOptions) (*comdirect.DepotTransactions, error)
+	PaginatedDepotTransactionsFunc   func(authToken *comdirect.AuthToken, depotID string, amount int, options *comdirect.DepotTransactionOptions) (*comdirect.DepotTransactions, error)
+	DepotTransactionPagesFunc        func(authToken *comdirect.AuthToken, depotID string, options *comdirect.DepotTransactionOptions) iter.Seq2[*comdirect.DepotTransactions, error]
+	DocumentsFunc                    func(token *comdirect.AuthToken, options *comdirect.DocumentsOptions) (*comdirect.Documents, error)
+	DocumentPagesFunc                func(token *comdirect.AuthToken, options *comdirect.DocumentsOptions) iter.Seq2[*comdirect.Documents, error]
+	DownloadDocumentFunc             func(token *comdirect.AuthToken, documentID string, mimeType string) (io.ReadCloser, error)
+}
+
+var (
+	_ comdirect.Authenticator = (*Client)(nil)
+	_ comdirect.Banking       = (*Client)(nil)
+	_ comdirect.Brokerage     = (*Client)(nil)
+	_ comdirect.Postbox       = (*Client)(nil)
+)
+
+func (m *Client) Authenticate(twoFaHandler func(tanHeader comdirect.TANHeader) error) (*comdirect.AuthToken, error) {
+	return m.AuthenticateFunc(twoFaHandler)
+}
+
+func (m *Client) RefreshToken(token *comdirect.AuthToken) (*comdirect.AuthToken, error) {
+	return m.RefreshTokenFunc(token)
+}
+
+func (m *Client) RevokeToken(token *comdirect.AuthToken) error {
+	return m.RevokeTokenFunc(token)
+}
+
+func (m *Client) AccountBalances(token *comdirect.AuthToken, options *comdirect.AccountBalancesOptions) (*comdirect.AccountBalances, error) {
+	return m.AccountBalancesFunc(token, options)
+}
+
+func (m *Client) AccountBalance(token *comdirect.AuthToken, accountID string) (*comdirect.AccountBalance, error) {
+	return m.AccountBalanceFunc(token, accountID)
+}
+
+func (m *Client) AccountTransactions(token *comdirect.AuthToken, accountID string, options *comdirect.AccountTransactionOptions) (*comdirect.AccountTransactions, error) {
+	return m.AccountTransactionsFunc(token, accountID, options)
+}
+
+func (m *Client) PaginatedAccountTransactions(token *comdirect.AuthToken, accountID string, amount int, options *comdirect.AccountTransactionOptions) (*comdirect.AccountTransactions, error) {
+	return m.PaginatedAccountTransactionsFunc(token, accountID, amount, options)
+}
+
+func (m *Client) Depots(authToken *comdirect.AuthToken, options *comdirect.DepotsOptions) (*comdirect.Depots, error) {
+	return m.DepotsFunc(authToken, options)
+}
+
+func (m *Client) PaginatedDepots(authToken *comdirect.AuthToken, amount int) (*comdirect.Depots, error) {
+	return m.PaginatedDepotsFunc(authToken, amount)
+}
+
+func (m *Client) DepotPositions(authToken *comdirect.AuthToken, depotID string, options *comdirect.DepotPosistionsOptions) (*comdirect.DepotPositions, error) {
+	return m.DepotPositionsFunc(authToken, depotID, options)
+}
+
+func (m *Client) PaginatedDepotPositions(authToken *comdirect.AuthToken, depotID string, amount int, options *comdirect.DepotPosistionsOptions) (*comdirect.DepotPositions, error) {
+	return m.PaginatedDepotPositionsFunc(authToken, depotID, amount, options)
+}
+
+func (m *Client) DepotPosition(authToken *comdirect.AuthToken, depotID string, positionID string, options *comdirect.DepotPositionOptions) (*comdirect.DepotPosition, error) {
+	return m.DepotPositionFunc(authToken, depotID, positionID, options)
+}
+
+func (m *Client) DepotTransactions(authToken *comdirect.AuthToken, depotID string, options *comdirect.DepotTransactionOptions) (*comdirect.DepotTransactions, error) {
+	return m.DepotTransactionsFunc(authToken, depotID, options)
+}
+
+func (m *Client) PaginatedDepotTransactions(authToken *comdirect.AuthToken, depotID string, amount int, options *comdirect.DepotTransactionOptions) (*comdirect.DepotTransactions, error) {
+	return m.PaginatedDepotTransactionsFunc(authToken, depotID, amount, options)
+}
+
+func (m *Client) Documents(token *comdirect.AuthToken, options *comdirect.DocumentsOptions) (*comdirect.Documents, error) {
+	return m.DocumentsFunc(token, options)
+}
+
+func (m *Client) DownloadDocument(token *comdirect.AuthToken, documentID string, mimeType string) (io.ReadCloser, error) {
+	return m.DownloadDocumentFunc(token, documentID, mimeType)
+}
+
+func (m *Client) AccountBalancePages(token *comdirect.AuthToken, options *comdirect.AccountBalancesOptions) iter.Seq2[*comdirect.AccountBalances, error] {
+	return m.AccountBalancePagesFunc(token, options)
+}
+
+func (m *Client) AccountTransactionPages(token *comdirect.AuthToken, accountID string, options *comdirect.AccountTransactionOptions) iter.Seq2[*comdirect.AccountTransactions, error] {
+	return m.AccountTransactionPagesFunc(token, accountID, options)
+}
+
+func (m *Client) DepotPages(authToken *comdirect.AuthToken, options *comdirect.DepotsOptions) iter.Seq2[*comdirect.Depots, error] {
+	return m.DepotPagesFunc(authToken, options)
+}
+
+func (m *Client) DepotPositionPages(authToken *comdirect.AuthToken, depotID string, options *comdirect.DepotPosistionsOptions) iter.Seq2[*comdirect.DepotPositions, error] {
+	return m.DepotPositionPagesFunc(authToken, depotID, options)
+}
+
+func (m *Client) DepotTransactionPages(authToken *comdirect.AuthToken, depotID string, options *comdirect.DepotTransactionOptions) iter.Seq2[*comdirect.DepotTransactions, error] {
+	return m.DepotTransactionPagesFunc(authToken, depotID, options)
+}
+
+func (m *Client) DocumentPages(token *comdirect.AuthToken, options *comdirect.DocumentsOptions) iter.Seq2[*comdirect.Documents, error] {
+	return m.DocumentPagesFunc(token, options)
+}
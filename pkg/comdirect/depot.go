@@ -1,7 +1,7 @@
 package comdirect
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"net/http"
 	"time"
@@ -9,38 +9,33 @@ import (
 
 type DepotsOptions struct {
 	PagingFirst int
+	PagingCount int
 }
 
 func (o *DepotsOptions) queryParams() []string {
-	queryParams := []string{}
-	if o.PagingFirst > 0 {
-		queryParams = append(queryParams, fmt.Sprintf("paging-first=%d", o.PagingFirst))
-	}
-	return queryParams
+	return pagingQueryParams(o.PagingFirst, o.PagingCount)
 }
 
 func (c *Client) Depots(authToken *AuthToken, options *DepotsOptions) (*Depots, error) {
+	return c.depots(context.Background(), authToken, options)
+}
+
+func (c *Client) depots(ctx context.Context, authToken *AuthToken, options *DepotsOptions) (*Depots, error) {
 	url := fmt.Sprintf("%s/brokerage/clients/user/v3/depots", c.config.APIURL)
 
 	if options != nil {
-		addQueryParams(url, options)
+		url = addQueryParams(url, options)
 	}
 
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	addXHTTPRequestInfoHeader(req, authToken.SessionGUID, authToken.RequestID)
 	req.Header.Add("Accept", "application/json")
 
-	resBody, _, err := c.authenticatedRequest(req, authToken, http.StatusOK)
-	if err != nil {
-		return nil, err
-	}
-
 	var depots Depots
-	if err := json.NewDecoder(resBody).Decode(&depots); err != nil {
+	if _, err := c.authenticatedRequest(req, authToken, http.StatusOK, &depots); err != nil {
 		return nil, err
 	}
 
@@ -48,52 +43,31 @@ func (c *Client) Depots(authToken *AuthToken, options *DepotsOptions) (*Depots,
 }
 
 func (c *Client) PaginatedDepots(authToken *AuthToken, amount int) (*Depots, error) {
-	options := &DepotsOptions{
-		PagingFirst: 0,
-	}
-	firstDepots, err := c.Depots(authToken, options)
+	return c.paginatedDepots(context.Background(), staticToken(authToken), amount)
+}
+
+func (c *Client) paginatedDepots(ctx context.Context, token tokenFunc, amount int) (*Depots, error) {
+	first, values, err := collect(c.depotPages(ctx, token, nil), amount, func(page *Depots) []Depot {
+		return page.Values
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	var data []Depots
-	data = append(data, *firstDepots)
-	for len(data) < amount/globalPageSize {
-		options.PagingFirst = len(data) * globalPageSize
-		page, err := c.Depots(authToken, options)
-		if err != nil {
-			return nil, err
-		}
-
-		if len(page.Values) == 0 {
-			break
-		}
-
-		data = append(data, *page)
-	}
-
-	depots := flattenDepots(data)
-	return &depots, nil
-}
-
-func flattenDepots(depots []Depots) Depots {
-	values := []Depot{}
-	for _, d := range depots {
-		values = append(values, d.Values...)
-	}
-	return Depots{
+	return &Depots{
 		Paging: Paging{
 			Index:   0,
-			Matches: len(values),
+			Matches: first.Paging.Matches,
 		},
 		Values: values,
-	}
+	}, nil
 }
 
 type DepotPosistionsOptions struct {
 	IncludeInstrument bool
 	ExcludeDepot      bool
 	PagingFirst       int
+	PagingCount       int
 }
 
 func (o *DepotPosistionsOptions) queryParams() []string {
@@ -104,36 +78,32 @@ func (o *DepotPosistionsOptions) queryParams() []string {
 	if o.ExcludeDepot {
 		queryParams = append(queryParams, fmt.Sprintf("%s=%s", excludeProperty, "depot"))
 	}
-	if o.PagingFirst > 0 {
-		queryParams = append(queryParams, fmt.Sprintf("paging-first=%d", o.PagingFirst))
-	}
+	queryParams = append(queryParams, pagingQueryParams(o.PagingFirst, o.PagingCount)...)
 	return queryParams
 }
 
 // DepotPositions returns the positions of a depot.
 // For more information see https://www.comdirect.de/cms/media/comdirect_REST_API_Dokumentation.pdf
 func (c *Client) DepotPositions(authToken *AuthToken, depotID string, options *DepotPosistionsOptions) (*DepotPositions, error) {
+	return c.depotPositions(context.Background(), authToken, depotID, options)
+}
+
+func (c *Client) depotPositions(ctx context.Context, authToken *AuthToken, depotID string, options *DepotPosistionsOptions) (*DepotPositions, error) {
 	url := fmt.Sprintf("%s/brokerage/v3/depots/%s/positions", c.config.APIURL, depotID)
 
 	if options != nil {
-		addQueryParams(url, options)
+		url = addQueryParams(url, options)
 	}
 
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	addXHTTPRequestInfoHeader(req, authToken.SessionGUID, authToken.RequestID)
 	req.Header.Add("Accept", "application/json")
 
-	resBody, _, err := c.authenticatedRequest(req, authToken, http.StatusOK)
-	if err != nil {
-		return nil, err
-	}
-
 	var depotPositions DepotPositions
-	if err := json.NewDecoder(resBody).Decode(&depotPositions); err != nil {
+	if _, err := c.authenticatedRequest(req, authToken, http.StatusOK, &depotPositions); err != nil {
 		return nil, err
 	}
 
@@ -141,47 +111,25 @@ func (c *Client) DepotPositions(authToken *AuthToken, depotID string, options *D
 }
 
 func (c *Client) PaginatedDepotPositions(authToken *AuthToken, depotID string, amount int, options *DepotPosistionsOptions) (*DepotPositions, error) {
-	if options == nil {
-		options = &DepotPosistionsOptions{}
-	}
-	options.PagingFirst = 0
-	firstPositions, err := c.DepotPositions(authToken, depotID, options)
+	return c.paginatedDepotPositions(context.Background(), staticToken(authToken), depotID, amount, options)
+}
+
+func (c *Client) paginatedDepotPositions(ctx context.Context, token tokenFunc, depotID string, amount int, options *DepotPosistionsOptions) (*DepotPositions, error) {
+	first, values, err := collect(c.depotPositionPages(ctx, token, depotID, options), amount, func(page *DepotPositions) []DepotPosition {
+		return page.Values
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	var data []DepotPositions
-	data = append(data, *firstPositions)
-	for len(data) < amount/globalPageSize {
-		options.PagingFirst = len(data) * globalPageSize
-		page, err := c.DepotPositions(authToken, depotID, options)
-		if err != nil {
-			return nil, err
-		}
-
-		if len(page.Values) == 0 {
-			break
-		}
-
-		data = append(data, *page)
-	}
-
-	positions := flattenDepotPositions(data)
-	return &positions, nil
-}
-
-func flattenDepotPositions(positions []DepotPositions) DepotPositions {
-	values := []DepotPosition{}
-	for _, p := range positions {
-		values = append(values, p.Values...)
-	}
-	return DepotPositions{
+	return &DepotPositions{
 		Paging: Paging{
-			Index:   0,
-			Matches: len(values),
+			Index:   first.Paging.Index,
+			Matches: first.Paging.Matches,
 		},
-		Values: values,
-	}
+		AggregatedPositions: first.AggregatedPositions,
+		Values:              values,
+	}, nil
 }
 
 type DepotPositionOptions struct {
@@ -199,27 +147,25 @@ func (o *DepotPositionOptions) queryParams() []string {
 // DepotPosition returns the position of a depot.
 // For more information see https://www.comdirect.de/cms/media/comdirect_REST_API_Dokumentation.pdf
 func (c *Client) DepotPosition(authToken *AuthToken, depotID string, positionID string, options *DepotPositionOptions) (*DepotPosition, error) {
+	return c.depotPosition(context.Background(), authToken, depotID, positionID, options)
+}
+
+func (c *Client) depotPosition(ctx context.Context, authToken *AuthToken, depotID string, positionID string, options *DepotPositionOptions) (*DepotPosition, error) {
 	url := fmt.Sprintf("%s/brokerage/v3/depots/%s/positions/%s", c.config.APIURL, depotID, positionID)
 
 	if options != nil {
-		addQueryParams(url, options)
+		url = addQueryParams(url, options)
 	}
 
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	addXHTTPRequestInfoHeader(req, authToken.SessionGUID, authToken.RequestID)
 	req.Header.Add("Accept", "application/json")
 
-	resBody, _, err := c.authenticatedRequest(req, authToken, http.StatusOK)
-	if err != nil {
-		return nil, err
-	}
-
 	var depotPosition DepotPosition
-	if err := json.NewDecoder(resBody).Decode(&depotPosition); err != nil {
+	if _, err := c.authenticatedRequest(req, authToken, http.StatusOK, &depotPosition); err != nil {
 		return nil, err
 	}
 
@@ -241,6 +187,7 @@ type DepotTransactionOptions struct {
 	BookingStatus  BookingStatus
 	MaxBookingDate time.Time
 	PagingFirst    int
+	PagingCount    int
 }
 
 func (dto *DepotTransactionOptions) queryParams() []string {
@@ -260,34 +207,30 @@ func (dto *DepotTransactionOptions) queryParams() []string {
 	if !dto.MaxBookingDate.IsZero() {
 		queryParams = append(queryParams, fmt.Sprintf("maxBookingDate=%s", dto.MaxBookingDate.Format("2006-01-02")))
 	}
-	if dto.PagingFirst > 0 {
-		queryParams = append(queryParams, fmt.Sprintf("paging-first=%d", dto.PagingFirst))
-	}
+	queryParams = append(queryParams, pagingQueryParams(dto.PagingFirst, dto.PagingCount)...)
 	return queryParams
 }
 
 func (c *Client) DepotTransactions(authToken *AuthToken, depotID string, options *DepotTransactionOptions) (*DepotTransactions, error) {
+	return c.depotTransactions(context.Background(), authToken, depotID, options)
+}
+
+func (c *Client) depotTransactions(ctx context.Context, authToken *AuthToken, depotID string, options *DepotTransactionOptions) (*DepotTransactions, error) {
 	url := fmt.Sprintf("%s/brokerage/v3/depots/%s/transactions", c.config.APIURL, depotID)
 
 	if options != nil {
-		addQueryParams(url, options)
+		url = addQueryParams(url, options)
 	}
 
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	addXHTTPRequestInfoHeader(req, authToken.SessionGUID, authToken.RequestID)
 	req.Header.Add("Accept", "application/json")
 
-	resBody, _, err := c.authenticatedRequest(req, authToken, http.StatusOK)
-	if err != nil {
-		return nil, err
-	}
-
 	var depotTransactions DepotTransactions
-	if err := json.NewDecoder(resBody).Decode(&depotTransactions); err != nil {
+	if _, err := c.authenticatedRequest(req, authToken, http.StatusOK, &depotTransactions); err != nil {
 		return nil, err
 	}
 
@@ -295,41 +238,22 @@ func (c *Client) DepotTransactions(authToken *AuthToken, depotID string, options
 }
 
 func (c *Client) PaginatedDepotTransactions(authToken *AuthToken, depotID string, amount int, options *DepotTransactionOptions) (*DepotTransactions, error) {
-	if options == nil {
-		options = &DepotTransactionOptions{}
-	}
-	options.PagingFirst = 0
-	firstPage, err := c.DepotTransactions(authToken, depotID, options)
+	return c.paginatedDepotTransactions(context.Background(), staticToken(authToken), depotID, amount, options)
+}
+
+func (c *Client) paginatedDepotTransactions(ctx context.Context, token tokenFunc, depotID string, amount int, options *DepotTransactionOptions) (*DepotTransactions, error) {
+	first, values, err := collect(c.depotTransactionPages(ctx, token, depotID, options), amount, func(page *DepotTransactions) []DepotTransaction {
+		return page.Values
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	transactions := []*DepotTransactions{firstPage}
-	for len(transactions) < amount/globalPageSize {
-		options.PagingFirst = len(transactions) * globalPageSize
-		page, err := c.DepotTransactions(authToken, depotID, options)
-		if err != nil {
-			return nil, err
-		}
-		if len(page.Values) == 0 {
-			break
-		}
-		transactions = append(transactions, page)
-	}
-
 	return &DepotTransactions{
 		Paging: Paging{
-			Index:   0,
-			Matches: len(transactions) * globalPageSize,
+			Index:   first.Paging.Index,
+			Matches: first.Paging.Matches,
 		},
-		Values: flattenDepotTransactions(transactions),
+		Values: values,
 	}, nil
 }
-
-func flattenDepotTransactions(transactions []*DepotTransactions) []DepotTransaction {
-	var flattened []DepotTransaction
-	for _, t := range transactions {
-		flattened = append(flattened, t.Values...)
-	}
-	return flattened
-}
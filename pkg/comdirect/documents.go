@@ -0,0 +1,70 @@
+package comdirect
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+type DocumentsOptions struct {
+	PagingFirst int
+	PagingCount int
+}
+
+func (o *DocumentsOptions) queryParams() []string {
+	return pagingQueryParams(o.PagingFirst, o.PagingCount)
+}
+
+// Documents returns the documents in the postbox of the user.
+// For more information see https://www.comdirect.de/cms/media/comdirect_REST_API_Dokumentation.pdf
+func (c *Client) Documents(token *AuthToken, options *DocumentsOptions) (*Documents, error) {
+	return c.documents(context.Background(), token, options)
+}
+
+func (c *Client) documents(ctx context.Context, token *AuthToken, options *DocumentsOptions) (*Documents, error) {
+	url := fmt.Sprintf("%s/messages/clients/user/v2/documents", c.config.APIURL)
+	if options != nil {
+		url = addQueryParams(url, options)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Accept", "application/json")
+
+	var documents Documents
+	if _, err := c.authenticatedRequest(req, token, http.StatusOK, &documents); err != nil {
+		return nil, err
+	}
+
+	return &documents, nil
+}
+
+// DownloadDocument streams the content of a document, e.g. a PDF.
+// The content is not buffered, so the caller is responsible for closing the returned reader.
+// The mimeType is taken from Document.MimeType.
+// For more information see https://www.comdirect.de/cms/media/comdirect_REST_API_Dokumentation.pdf
+func (c *Client) DownloadDocument(token *AuthToken, documentID string, mimeType string) (io.ReadCloser, error) {
+	return c.downloadDocument(context.Background(), token, documentID, mimeType)
+}
+
+func (c *Client) downloadDocument(ctx context.Context, token *AuthToken, documentID string, mimeType string) (io.ReadCloser, error) {
+	url := fmt.Sprintf("%s/messages/v2/documents/%s", c.config.APIURL, documentID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Accept", mimeType)
+
+	res, err := c.authenticatedResponse(req, token, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Body, nil
+}
@@ -1,3 +1,20 @@
 package comdirect
 
+import "fmt"
+
 const LockedTokenError = "token is locked"
+
+// RequestError is returned if a request is answered with an unexpected status code.
+type RequestError struct {
+	StatusCode int
+	Method     string
+	Path       string
+	RequestID  string
+}
+
+func (e *RequestError) Error() string {
+	if e.RequestID == "" {
+		return fmt.Sprintf("request failed with status code %d", e.StatusCode)
+	}
+	return fmt.Sprintf("request failed with status code %d (request id %s)", e.StatusCode, e.RequestID)
+}
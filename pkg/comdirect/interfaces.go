@@ -0,0 +1,51 @@
+package comdirect
+
+import (
+	"io"
+	"iter"
+)
+
+// Authenticator creates, refreshes and revokes tokens.
+type Authenticator interface {
+	Authenticate(twoFaHandler func(tanHeader TANHeader) error) (*AuthToken, error)
+	RefreshToken(token *AuthToken) (*AuthToken, error)
+	RevokeToken(token *AuthToken) error
+}
+
+// Banking provides access to the accounts of the user.
+type Banking interface {
+	AccountBalances(token *AuthToken, options *AccountBalancesOptions) (*AccountBalances, error)
+	AccountBalancePages(token *AuthToken, options *AccountBalancesOptions) iter.Seq2[*AccountBalances, error]
+	AccountBalance(token *AuthToken, accountID string) (*AccountBalance, error)
+	AccountTransactions(token *AuthToken, accountID string, options *AccountTransactionOptions) (*AccountTransactions, error)
+	PaginatedAccountTransactions(token *AuthToken, accountID string, amount int, options *AccountTransactionOptions) (*AccountTransactions, error)
+	AccountTransactionPages(token *AuthToken, accountID string, options *AccountTransactionOptions) iter.Seq2[*AccountTransactions, error]
+}
+
+// Brokerage provides access to the depots of the user.
+type Brokerage interface {
+	Depots(authToken *AuthToken, options *DepotsOptions) (*Depots, error)
+	PaginatedDepots(authToken *AuthToken, amount int) (*Depots, error)
+	DepotPages(authToken *AuthToken, options *DepotsOptions) iter.Seq2[*Depots, error]
+	DepotPositions(authToken *AuthToken, depotID string, options *DepotPosistionsOptions) (*DepotPositions, error)
+	PaginatedDepotPositions(authToken *AuthToken, depotID string, amount int, options *DepotPosistionsOptions) (*DepotPositions, error)
+	DepotPositionPages(authToken *AuthToken, depotID string, options *DepotPosistionsOptions) iter.Seq2[*DepotPositions, error]
+	DepotPosition(authToken *AuthToken, depotID string, positionID string, options *DepotPositionOptions) (*DepotPosition, error)
+	DepotTransactions(authToken *AuthToken, depotID string, options *DepotTransactionOptions) (*DepotTransactions, error)
+	PaginatedDepotTransactions(authToken *AuthToken, depotID string, amount int, options *DepotTransactionOptions) (*DepotTransactions, error)
+	DepotTransactionPages(authToken *AuthToken, depotID string, options *DepotTransactionOptions) iter.Seq2[*DepotTransactions, error]
+}
+
+// Postbox provides access to the documents of the user.
+type Postbox interface {
+	Documents(token *AuthToken, options *DocumentsOptions) (*Documents, error)
+	DocumentPages(token *AuthToken, options *DocumentsOptions) iter.Seq2[*Documents, error]
+	DownloadDocument(token *AuthToken, documentID string, mimeType string) (io.ReadCloser, error)
+}
+
+var (
+	_ Authenticator = (*Client)(nil)
+	_ Banking       = (*Client)(nil)
+	_ Brokerage     = (*Client)(nil)
+	_ Postbox       = (*Client)(nil)
+)
@@ -0,0 +1,139 @@
+package comdirect
+
+import (
+	"log/slog"
+)
+
+// logBody returns the body prepared for logging, redacted unless WithUnredactedLogs is set.
+func (c *Client) logBody(body []byte, contentType string) string {
+	if c.unredactedLogs {
+		return string(body)
+	}
+	return redactBody(body, contentType, logRedactedFields)
+}
+
+func (t *AuthToken) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("accessToken", maskSecret(t.AccessToken)),
+		slog.String("refreshToken", maskSecret(t.RefreshToken)),
+		slog.Int("expiresIn", t.ExpiresIn),
+		slog.Time("creationTime", t.CreationTime),
+		slog.String("scope", t.Scope),
+		slog.String("sessionGUID", t.SessionGUID),
+		slog.String("tanType", t.TANType),
+		slog.String("kdnr", maskSecret(t.KDNR)),
+	)
+}
+
+func (c Config) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("apiURL", c.APIURL),
+		slog.String("tokenURL", c.TokenURL),
+		slog.String("revokeTokenURL", c.RevokeTokenURL),
+		slog.String("clientID", c.ClientID),
+		slog.String("clientSecret", maskSecret(c.ClientSecret)),
+		slog.String("zugangsnummer", maskSecret(c.Zugangsnummer)),
+		slog.String("pin", maskSecret(c.Pin)),
+	)
+}
+
+func (b Balance) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("value", maskSecret(b.Value)),
+		slog.String("unit", b.Unit),
+	)
+}
+
+func (a Account) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("accountId", a.AccountID),
+		slog.String("accountType", a.AccountType.Key),
+		slog.String("currency", a.Currency),
+		slog.String("iban", maskIBAN(a.IBAN)),
+	)
+}
+
+func (a AccountBalance) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("accountId", a.AccountID),
+		slog.Any("account", a.Account),
+		slog.Any("balance", a.Balance),
+		slog.Any("availableCashAmount", a.AvailableCashAmount),
+	)
+}
+
+func (a AccountBalances) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("matches", a.Paging.Matches),
+		slog.Int("values", len(a.Values)),
+	)
+}
+
+func (c Creditor) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("holderName", maskSecret(c.HolderName)),
+		slog.String("iban", maskIBAN(c.IBAN)),
+	)
+}
+
+func (a AccountInformation) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("holderName", maskSecret(a.HolderName)),
+		slog.String("iban", maskIBAN(a.IBAN)),
+	)
+}
+
+func (t AccountTransaction) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("reference", t.Reference),
+		slog.String("bookingStatus", t.BookingStatus),
+		slog.String("bookingDate", t.BookingDate),
+		slog.Any("amount", t.Amount),
+		slog.String("transactionType", t.TransactionType.Key),
+	)
+}
+
+func (t AccountTransactions) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("matches", t.Paging.Matches),
+		slog.Int("values", len(t.Values)),
+	)
+}
+
+func (p DepotPosition) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("depotId", p.DepotID),
+		slog.String("positionId", p.PositionID),
+		slog.String("wkn", p.WKN),
+		slog.Any("quantity", p.Quantity),
+		slog.Any("currentValue", p.CurrentValue),
+		slog.Any("purchaseValue", p.PurchaseValue),
+	)
+}
+
+func (p DepotPositions) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("matches", p.Paging.Matches),
+		slog.Int("values", len(p.Values)),
+		slog.Any("currentValue", p.AggregatedPositions.CurrentValue),
+	)
+}
+
+func (t DepotTransaction) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("transactionId", t.TransactionID),
+		slog.String("bookingStatus", t.BookingStatus),
+		slog.String("bookingDate", t.BookingDate),
+		slog.String("wkn", t.Instrument.WKN),
+		slog.Any("quantity", t.Quantity),
+		slog.Any("transactionValue", t.TransactionValue),
+		slog.String("transactionType", t.TransactionType),
+	)
+}
+
+func (t DepotTransactions) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("matches", t.Paging.Matches),
+		slog.Int("values", len(t.Values)),
+	)
+}
@@ -0,0 +1,114 @@
+package comdirect
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Option configures a Client, see NewClient.
+type Option func(*Client)
+
+// Clock provides the current time to the Client.
+// It is used for token expiry checks and the request monitor.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// RetryPolicy defines how failed requests are retried.
+// A request is retried if the transport fails or the response status code is part of RetryOn.
+// The backoff is doubled after each attempt.
+type RetryPolicy struct {
+	MaxRetries int
+	Backoff    time.Duration
+	RetryOn    []int
+}
+
+// DefaultRetryOn are the status codes retried if RetryPolicy.RetryOn is empty.
+var DefaultRetryOn = []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+func (p RetryPolicy) shouldRetry(statusCode int) bool {
+	retryOn := p.RetryOn
+	if len(retryOn) == 0 {
+		retryOn = DefaultRetryOn
+	}
+	for _, code := range retryOn {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// WithHTTPClient sets the http client used for all requests.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.client = client
+	}
+}
+
+// WithTransport sets the transport of the http client, e.g. for proxies, mTLS or instrumentation.
+// It is combined with WithHTTPClient in any order, the http client passed there is not modified.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.transport = transport
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithRateLimit sets the amount of requests per second after which the client warns.
+func WithRateLimit(requestsPerSecond int) Option {
+	return func(c *Client) {
+		c.requestLimitPerSecond = requestsPerSecond
+	}
+}
+
+// WithRetryPolicy sets the policy for retrying failed requests. By default requests are not retried.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+// WithClock sets the clock used by the client.
+func WithClock(clock Clock) Option {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+// WithLogger sets the logger used by the client, by default slog.Default is used.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithTokenStore persists every new or refreshed token in store under key.
+// Revoked tokens are deleted from the store. Use RestoreSession to continue with the stored token.
+func WithTokenStore(store TokenStore, key string) Option {
+	return func(c *Client) {
+		c.store = store
+		c.storeKey = key
+	}
+}
+
+// WithUnredactedLogs disables the redaction of request and response bodies in debug logs.
+// Only use this for debugging, as tokens, PINs and account data will be part of the logs.
+func WithUnredactedLogs() Option {
+	return func(c *Client) {
+		c.unredactedLogs = true
+	}
+}
@@ -0,0 +1,50 @@
+package comdirect
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWithTransportIsIndependentOfOptionOrder(t *testing.T) {
+	transport := staticTransport{body: []byte(`{}`)}
+	for name, opts := range map[string][]Option{
+		"transport first": {WithTransport(transport), WithHTTPClient(http.DefaultClient)},
+		"transport last":  {WithHTTPClient(http.DefaultClient), WithTransport(transport)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			client := NewClient(Config{}, opts...)
+			if _, ok := client.client.Transport.(staticTransport); !ok {
+				t.Errorf("transport was not applied, got %T", client.client.Transport)
+			}
+			if http.DefaultClient.Transport != nil {
+				t.Errorf("http.DefaultClient was modified")
+			}
+		})
+	}
+}
+
+func TestRecorderLogsWithClientLogger(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	recorder, err := NewRecorder(t.TempDir(), staticTransport{body: []byte(`{}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(Config{}, WithTransport(recorder), WithLogger(logger))
+
+	req, err := http.NewRequest(http.MethodGet, "https://api.example.com/api/banking/clients/user/v2/accounts/balances", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := client.client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if !strings.Contains(logs.String(), "Recording interaction 0001_get.json") {
+		t.Errorf("recorder did not log with the client logger: %q", logs.String())
+	}
+}
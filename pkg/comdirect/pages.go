@@ -0,0 +1,190 @@
+package comdirect
+
+import (
+	"context"
+	"iter"
+)
+
+// page is implemented by the responses of list endpoints.
+type page interface {
+	paging() (Paging, int)
+}
+
+func (a *AccountBalances) paging() (Paging, int)     { return a.Paging, len(a.Values) }
+func (a *AccountTransactions) paging() (Paging, int) { return a.Paging, len(a.Values) }
+func (d *Depots) paging() (Paging, int)              { return d.Paging, len(d.Values) }
+func (d *DepotPositions) paging() (Paging, int)      { return d.Paging, len(d.Values) }
+func (d *DepotTransactions) paging() (Paging, int)   { return d.Paging, len(d.Values) }
+func (d *Documents) paging() (Paging, int)           { return d.Paging, len(d.Values) }
+
+// tokenFunc returns the token for the next request, a session refreshes it while iterating over many pages.
+type tokenFunc func(ctx context.Context) (*AuthToken, error)
+
+func staticToken(token *AuthToken) tokenFunc {
+	return func(context.Context) (*AuthToken, error) {
+		return token, nil
+	}
+}
+
+// pages iterates over the pages of a list, fetch returns the page starting with the item at index first.
+// A page is only requested once the previous one has been consumed.
+// The iteration ends after an empty page, the page containing the last match or the first error.
+func pages[P page](first int, fetch func(first int) (P, error)) iter.Seq2[P, error] {
+	return func(yield func(P, error) bool) {
+		for {
+			page, err := fetch(first)
+			if err != nil {
+				var zero P
+				yield(zero, err)
+				return
+			}
+			// read the paging first, the caller may modify the values of the page
+			paging, count := page.paging()
+			if !yield(page, nil) {
+				return
+			}
+			first += count
+			if count == 0 || (paging.Matches > 0 && first >= paging.Matches) {
+				return
+			}
+		}
+	}
+}
+
+// collect requests pages until amount values are loaded, an amount of 0 or less loads all pages.
+// The values of the last page are truncated to amount.
+func collect[P page, V any](pages iter.Seq2[P, error], amount int, values func(P) []V) (first P, all []V, err error) {
+	for page, err := range pages {
+		if err != nil {
+			return first, nil, err
+		}
+		if len(all) == 0 {
+			first = page
+		}
+		all = append(all, values(page)...)
+		if amount > 0 && len(all) >= amount {
+			return first, all[:amount], nil
+		}
+	}
+	return first, all, nil
+}
+
+// AccountBalancePages returns an iterator over the pages of balances of all accounts starting at options.PagingFirst.
+func (c *Client) AccountBalancePages(token *AuthToken, options *AccountBalancesOptions) iter.Seq2[*AccountBalances, error] {
+	return c.accountBalancePages(context.Background(), staticToken(token), options)
+}
+
+func (c *Client) accountBalancePages(ctx context.Context, token tokenFunc, options *AccountBalancesOptions) iter.Seq2[*AccountBalances, error] {
+	pageOptions := AccountBalancesOptions{}
+	if options != nil {
+		pageOptions = *options
+	}
+	return pages(pageOptions.PagingFirst, func(first int) (*AccountBalances, error) {
+		authToken, err := token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		pageOptions.PagingFirst = first
+		return c.accountBalances(ctx, authToken, &pageOptions)
+	})
+}
+
+// AccountTransactionPages returns an iterator over the pages of transactions of an account starting at options.PagingFirst.
+func (c *Client) AccountTransactionPages(token *AuthToken, accountID string, options *AccountTransactionOptions) iter.Seq2[*AccountTransactions, error] {
+	return c.accountTransactionPages(context.Background(), staticToken(token), accountID, options)
+}
+
+func (c *Client) accountTransactionPages(ctx context.Context, token tokenFunc, accountID string, options *AccountTransactionOptions) iter.Seq2[*AccountTransactions, error] {
+	pageOptions := AccountTransactionOptions{}
+	if options != nil {
+		pageOptions = *options
+	}
+	return pages(pageOptions.PagingFirst, func(first int) (*AccountTransactions, error) {
+		authToken, err := token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		pageOptions.PagingFirst = first
+		return c.accountTransactions(ctx, authToken, accountID, &pageOptions)
+	})
+}
+
+// DepotPages returns an iterator over the pages of depots starting at options.PagingFirst.
+func (c *Client) DepotPages(authToken *AuthToken, options *DepotsOptions) iter.Seq2[*Depots, error] {
+	return c.depotPages(context.Background(), staticToken(authToken), options)
+}
+
+func (c *Client) depotPages(ctx context.Context, token tokenFunc, options *DepotsOptions) iter.Seq2[*Depots, error] {
+	pageOptions := DepotsOptions{}
+	if options != nil {
+		pageOptions = *options
+	}
+	return pages(pageOptions.PagingFirst, func(first int) (*Depots, error) {
+		authToken, err := token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		pageOptions.PagingFirst = first
+		return c.depots(ctx, authToken, &pageOptions)
+	})
+}
+
+// DepotPositionPages returns an iterator over the pages of positions of a depot starting at options.PagingFirst.
+func (c *Client) DepotPositionPages(authToken *AuthToken, depotID string, options *DepotPosistionsOptions) iter.Seq2[*DepotPositions, error] {
+	return c.depotPositionPages(context.Background(), staticToken(authToken), depotID, options)
+}
+
+func (c *Client) depotPositionPages(ctx context.Context, token tokenFunc, depotID string, options *DepotPosistionsOptions) iter.Seq2[*DepotPositions, error] {
+	pageOptions := DepotPosistionsOptions{}
+	if options != nil {
+		pageOptions = *options
+	}
+	return pages(pageOptions.PagingFirst, func(first int) (*DepotPositions, error) {
+		authToken, err := token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		pageOptions.PagingFirst = first
+		return c.depotPositions(ctx, authToken, depotID, &pageOptions)
+	})
+}
+
+// DepotTransactionPages returns an iterator over the pages of transactions of a depot starting at options.PagingFirst.
+func (c *Client) DepotTransactionPages(authToken *AuthToken, depotID string, options *DepotTransactionOptions) iter.Seq2[*DepotTransactions, error] {
+	return c.depotTransactionPages(context.Background(), staticToken(authToken), depotID, options)
+}
+
+func (c *Client) depotTransactionPages(ctx context.Context, token tokenFunc, depotID string, options *DepotTransactionOptions) iter.Seq2[*DepotTransactions, error] {
+	pageOptions := DepotTransactionOptions{}
+	if options != nil {
+		pageOptions = *options
+	}
+	return pages(pageOptions.PagingFirst, func(first int) (*DepotTransactions, error) {
+		authToken, err := token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		pageOptions.PagingFirst = first
+		return c.depotTransactions(ctx, authToken, depotID, &pageOptions)
+	})
+}
+
+// DocumentPages returns an iterator over the pages of documents in the postbox starting at options.PagingFirst.
+func (c *Client) DocumentPages(token *AuthToken, options *DocumentsOptions) iter.Seq2[*Documents, error] {
+	return c.documentPages(context.Background(), staticToken(token), options)
+}
+
+func (c *Client) documentPages(ctx context.Context, token tokenFunc, options *DocumentsOptions) iter.Seq2[*Documents, error] {
+	pageOptions := DocumentsOptions{}
+	if options != nil {
+		pageOptions = *options
+	}
+	return pages(pageOptions.PagingFirst, func(first int) (*Documents, error) {
+		authToken, err := token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		pageOptions.PagingFirst = first
+		return c.documents(ctx, authToken, &pageOptions)
+	})
+}
@@ -0,0 +1,223 @@
+package comdirect
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Interaction is a single recorded request/response pair.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header"`
+	Body   string      `json:"body"`
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+}
+
+// Recorder is a http.RoundTripper that forwards requests to the next transport
+// and stores every request/response pair as a redacted fixture file in dir.
+// Tokens, PINs, IBANs and holder names are replaced before anything is written to disk.
+// A Recorder passed to WithTransport logs with the logger of the client, see WithLogger.
+type Recorder struct {
+	dir    string
+	next   http.RoundTripper
+	logger *slog.Logger
+	mu     sync.Mutex
+	count  int
+}
+
+// NewRecorder creates a Recorder writing fixtures to dir.
+// If next is nil, http.DefaultTransport is used.
+func NewRecorder(dir string, next http.RoundTripper) (*Recorder, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Recorder{dir: dir, next: next}, nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readAndRestoreRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resBody, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: redactHeader(req.Header),
+			Body:   redactBody(reqBody, req.Header.Get("Content-Type"), redactedFields),
+		},
+		Response: RecordedResponse{
+			StatusCode: res.StatusCode,
+			Header:     redactHeader(res.Header),
+			Body:       redactBody(resBody, res.Header.Get("Content-Type"), redactedFields),
+		},
+	}
+
+	if err := r.save(interaction); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *Recorder) save(interaction Interaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.count++
+	name := fmt.Sprintf("%04d_%s.json", r.count, strings.ToLower(interaction.Request.Method))
+	r.log().Debug(fmt.Sprintf("Recording interaction %s", name))
+
+	data, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(r.dir, name), data, 0600)
+}
+
+// log returns the logger of the client the recorder is used by, or slog.Default if it is used on its own.
+func (r *Recorder) log() *slog.Logger {
+	if r.logger == nil {
+		return slog.Default()
+	}
+	return r.logger
+}
+
+// Replayer is a http.RoundTripper that serves the fixtures written by a Recorder.
+// Interactions are replayed strictly in the recorded order, a request that does not
+// match the next recorded method and path results in an error.
+// UUIDs in the path, like the session GUID generated on every login, match any UUID.
+type Replayer struct {
+	interactions []Interaction
+	mu           sync.Mutex
+	position     int
+}
+
+// NewReplayer loads all fixtures from dir.
+func NewReplayer(dir string) (*Replayer, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	interactions := make([]Interaction, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var interaction Interaction
+		if err := json.Unmarshal(data, &interaction); err != nil {
+			return nil, fmt.Errorf("invalid fixture %s: %w", file, err)
+		}
+		interactions = append(interactions, interaction)
+	}
+
+	return &Replayer{interactions: interactions}, nil
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.position >= len(r.interactions) {
+		return nil, fmt.Errorf("no recorded interaction left for %s %s", req.Method, req.URL.Path)
+	}
+	interaction := r.interactions[r.position]
+
+	recordedURL, err := url.Parse(interaction.Request.URL)
+	if err != nil {
+		return nil, err
+	}
+	if interaction.Request.Method != req.Method || normalizePath(recordedURL.Path) != normalizePath(req.URL.Path) {
+		return nil, fmt.Errorf("unexpected request %s %s, recorded interaction %d is %s %s", req.Method, req.URL.Path, r.position+1, interaction.Request.Method, recordedURL.Path)
+	}
+	r.position++
+
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+		StatusCode:    interaction.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        interaction.Response.Header.Clone(),
+		Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+		ContentLength: int64(len(interaction.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// Remaining returns the number of recorded interactions which have not been replayed yet.
+func (r *Replayer) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.interactions) - r.position
+}
+
+// normalizePath replaces every UUID segment of the path, so paths of different sessions can be compared.
+func normalizePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if len(segment) == 36 && uuid.Validate(segment) == nil {
+			segments[i] = "{uuid}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func readAndRestoreRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
@@ -0,0 +1,145 @@
+package comdirect
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	testSessionID = "6f1c2d3e-4b5a-4c7d-8e9f-0a1b2c3d4e5f"
+	testIBAN      = "DE02120300000000202051"
+	testHolder    = "Erika Mustermann"
+)
+
+// newLoginServer serves a complete login and one page of account transactions.
+func newLoginServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	sessionBody := `{"identifier":"` + testSessionID + `","sessionTanActive":true,"activated2FA":true}`
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/oauth/token":
+			r.ParseForm()
+			if r.PostForm.Get("grant_type") == "password" {
+				w.Write([]byte(`{"access_token":"initial-access","refresh_token":"initial-refresh","expires_in":599,"kdnr":"9876543210","bpid":111,"kontaktId":222}`))
+				return
+			}
+			w.Write([]byte(`{"access_token":"secondary-access","refresh_token":"secondary-refresh","expires_in":599}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/session/clients/user/v1/sessions":
+			w.Write([]byte(`[{"identifier":"` + testSessionID + `","sessionTanActive":false,"activated2FA":false}]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/session/clients/user/v1/sessions/"+testSessionID+"/validate":
+			w.Header().Set(xOnceAuthenticationInfoHeader, `{"id":"challenge","typ":"P_TAN_PUSH"}`)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(sessionBody))
+		case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/api/session/clients/user/v1/sessions/"):
+			w.Write([]byte(sessionBody))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/banking/v1/accounts/account/transactions":
+			w.Write([]byte(`{"paging":{"index":0,"matches":1},"values":[{"reference":"ref","remitter":{"holderName":"` + testHolder + `","iban":"` + testIBAN + `"}}]}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func loginAndListTransactions(t *testing.T, config Config, transport http.RoundTripper) *AccountTransactions {
+	t.Helper()
+	client := NewClient(config, WithTransport(transport))
+	token, err := client.Authenticate(func(TANHeader) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	transactions, err := client.AccountTransactions(token, "account", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return transactions
+}
+
+func TestRecordAndReplayLogin(t *testing.T) {
+	server := newLoginServer(t)
+	defer server.Close()
+	config := Config{
+		APIURL:        server.URL + "/api",
+		TokenURL:      server.URL + "/oauth/token",
+		ClientID:      "client-id",
+		ClientSecret:  "client-secret",
+		Zugangsnummer: "12345678",
+		Pin:           "424242",
+	}
+
+	dir := t.TempDir()
+	recorder, err := NewRecorder(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded := loginAndListTransactions(t, config, recorder)
+	if recorded.Values[0].Remitter.HolderName != testHolder {
+		t.Errorf("recorder changed the response: %q", recorded.Values[0].Remitter.HolderName)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 6 {
+		t.Fatalf("expected 6 recorded interactions, got %d", len(files))
+	}
+	secrets := []string{
+		"initial-access", "initial-refresh", "secondary-access", "secondary-refresh",
+		"client-secret", "12345678", "424242", "9876543210", testIBAN, testHolder,
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var interaction Interaction
+		if err := json.Unmarshal(data, &interaction); err != nil {
+			t.Fatal(err)
+		}
+		for _, secret := range secrets {
+			if strings.Contains(string(data), secret) {
+				t.Errorf("%s contains %q", filepath.Base(file), secret)
+			}
+		}
+	}
+
+	// the replay runs without the server, every login generates a new session GUID
+	server.Close()
+	replayer, err := NewReplayer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed := loginAndListTransactions(t, config, replayer)
+	if replayer.Remaining() != 0 {
+		t.Errorf("%d interactions were not replayed", replayer.Remaining())
+	}
+	if len(replayed.Values) != 1 || replayed.Values[0].Reference != "ref" {
+		t.Errorf("unexpected replayed transactions: %+v", replayed.Values)
+	}
+	if replayed.Values[0].Remitter.HolderName != redacted {
+		t.Errorf("replayed holder name is not redacted: %q", replayed.Values[0].Remitter.HolderName)
+	}
+}
+
+func TestReplayerRejectsUnexpectedRequest(t *testing.T) {
+	dir := t.TempDir()
+	interaction := `{"request":{"method":"GET","url":"https://api.example.com/api/banking/v1/accounts/a/transactions"},"response":{"statusCode":200,"body":"{}"}}`
+	if err := os.WriteFile(filepath.Join(dir, "0001_get.json"), []byte(interaction), 0600); err != nil {
+		t.Fatal(err)
+	}
+	replayer, err := NewReplayer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "https://api.example.com/api/banking/v1/accounts/b/transactions", nil)
+	if _, err := replayer.RoundTrip(req); err == nil {
+		t.Error("expected an error for a request to another account")
+	}
+}
@@ -0,0 +1,148 @@
+package comdirect
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const redacted = "REDACTED"
+
+var (
+	ibanPattern = regexp.MustCompile(`\b[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}\b`)
+
+	redactedHeaders = []string{authorizationHeader, XOnceAuthenticationHeader, "Set-Cookie"}
+
+	// redactedFields are the fields replaced in recorded fixtures.
+	redactedFields = map[string]bool{
+		"access_token":  true,
+		"refresh_token": true,
+		"token":         true,
+		"client_secret": true,
+		"password":      true,
+		"username":      true,
+		"pin":           true,
+		"kdnr":          true,
+		"bpid":          true,
+		"kontaktid":     true,
+		"iban":          true,
+		"holdername":    true,
+	}
+
+	// logRedactedFields are the fields replaced in log output,
+	// in addition to redactedFields amounts and remittance information are hidden.
+	logRedactedFields = union(redactedFields, map[string]bool{
+		"value":          true,
+		"remittanceinfo": true,
+	})
+)
+
+func union(a, b map[string]bool) map[string]bool {
+	result := make(map[string]bool, len(a)+len(b))
+	for key := range a {
+		result[key] = true
+	}
+	for key := range b {
+		result[key] = true
+	}
+	return result
+}
+
+// maskSecret hides a secret completely.
+func maskSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redacted
+}
+
+// maskIBAN keeps the country code and the last four characters of an IBAN.
+func maskIBAN(iban string) string {
+	if len(iban) < 8 {
+		return maskSecret(iban)
+	}
+	return iban[:2] + strings.Repeat("*", len(iban)-6) + iban[len(iban)-4:]
+}
+
+func redactHeader(header http.Header) http.Header {
+	clone := header.Clone()
+	// bodies are rewritten during redaction, so the original length no longer applies
+	clone.Del("Content-Length")
+	for _, key := range redactedHeaders {
+		if clone.Get(key) != "" {
+			clone.Set(key, redacted)
+		}
+	}
+	return clone
+}
+
+// redactBody replaces the values of all fields in the body which are part of fields.
+// Form and JSON bodies are supported, IBANs are replaced in any body.
+func redactBody(body []byte, contentType string, fields map[string]bool) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		values, err := url.ParseQuery(string(body))
+		if err == nil {
+			for key := range values {
+				if fields[strings.ToLower(key)] {
+					values.Set(key, redacted)
+				}
+			}
+			return values.Encode()
+		}
+	}
+
+	var parsed interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&parsed); err == nil {
+		data, err := json.Marshal(redactValue(parsed, fields))
+		if err == nil {
+			return string(data)
+		}
+	}
+
+	return ibanPattern.ReplaceAllString(string(body), redacted)
+}
+
+func redactValue(value interface{}, fields map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if fields[strings.ToLower(key)] {
+				v[key] = redactScalar(child, fields)
+				continue
+			}
+			v[key] = redactValue(child, fields)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = redactValue(child, fields)
+		}
+		return v
+	case string:
+		return ibanPattern.ReplaceAllString(v, redacted)
+	default:
+		return v
+	}
+}
+
+func redactScalar(value interface{}, fields map[string]bool) interface{} {
+	switch value.(type) {
+	case json.Number:
+		return json.Number("0")
+	case string:
+		return redacted
+	case nil:
+		return nil
+	default:
+		return redactValue(value, fields)
+	}
+}
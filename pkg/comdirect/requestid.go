@@ -0,0 +1,76 @@
+package comdirect
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	mathrand "math/rand/v2"
+	"net/http"
+)
+
+type (
+	correlationIDKey struct{}
+	requestIDKey     struct{}
+)
+
+// requestIDDigits is the length of the request id sent to comdirect.
+const requestIDDigits = 9
+
+// WithRequestID returns a context carrying a correlation id, e.g. to find the requests of a job in the logs.
+// Every request sent with this context still gets its own request id, which is prefixed with the correlation id
+// in responses, errors and logs, like nightly-042137965. Only the nine random digits are sent to comdirect.
+func WithRequestID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, correlationID)
+}
+
+// RequestIDFromContext returns the request id carried by the context.
+// The context of every request sent by the client carries its request id,
+// so it is available to custom transports and log handlers.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDKey{}).(string)
+	return requestID, ok
+}
+
+// requestIDOf returns the request id carried by the context or an empty string.
+func requestIDOf(ctx context.Context) string {
+	requestID, _ := RequestIDFromContext(ctx)
+	return requestID
+}
+
+// withRequestID makes sure the request context carries a request id and returns the digits sent to comdirect.
+func withRequestID(req *http.Request) (*http.Request, string) {
+	if requestID, ok := RequestIDFromContext(req.Context()); ok {
+		return req, requestID[len(requestID)-requestIDDigits:]
+	}
+	sent := newRequestID()
+	requestID := sent
+	if correlationID, ok := req.Context().Value(correlationIDKey{}).(string); ok && correlationID != "" {
+		requestID = correlationID + "-" + sent
+	}
+	return req.WithContext(context.WithValue(req.Context(), requestIDKey{}, requestID)), sent
+}
+
+// newRequestID returns a random nine digit request id as expected by comdirect.
+func newRequestID() string {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000000))
+	if err != nil {
+		// the id only has to be unlikely to repeat, it is not a secret
+		return fmt.Sprintf("%0*d", requestIDDigits, mathrand.Int64N(1000000000))
+	}
+	return fmt.Sprintf("%0*d", requestIDDigits, n.Int64())
+}
+
+// ResponseMeta holds information about the request a response was received for.
+// It is embedded in all response types and not part of their JSON representation.
+type ResponseMeta struct {
+	RequestID string `json:"-"`
+}
+
+type responseMeta interface {
+	setRequestID(requestID string)
+}
+
+func (m *ResponseMeta) setRequestID(requestID string) {
+	m.RequestID = requestID
+}
@@ -0,0 +1,78 @@
+package comdirect
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var requestIDPattern = regexp.MustCompile(`^[0-9]{9}$`)
+
+// requestIDTransport records the request id of the context and the one sent in the x-http-request-info header.
+type requestIDTransport struct {
+	staticTransport
+	contextIDs []string
+	sentIDs    []string
+}
+
+func (t *requestIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestID, _ := RequestIDFromContext(req.Context())
+	t.contextIDs = append(t.contextIDs, requestID)
+	var info struct {
+		ClientRequestID struct {
+			RequestID string `json:"requestId"`
+		} `json:"clientRequestId"`
+	}
+	json.Unmarshal([]byte(req.Header.Get(xHTTPRequestInfoHeader)), &info)
+	t.sentIDs = append(t.sentIDs, info.ClientRequestID.RequestID)
+	return t.staticTransport.RoundTrip(req)
+}
+
+func TestNewRequestID(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		requestID := newRequestID()
+		if !requestIDPattern.MatchString(requestID) {
+			t.Fatalf("request id %q is not nine digits", requestID)
+		}
+		if seen[requestID] {
+			t.Fatalf("request id %q was generated twice", requestID)
+		}
+		seen[requestID] = true
+	}
+}
+
+func TestWithRequestIDPrefixesEveryRequest(t *testing.T) {
+	transport := &requestIDTransport{staticTransport: staticTransport{body: []byte(`{"values":[]}`)}}
+	client, token := newBenchmarkClient(nil)
+	client.client.Transport = transport
+
+	ctx := WithRequestID(context.Background(), "nightly")
+	var responseIDs []string
+	for i := 0; i < 2; i++ {
+		balances, err := client.accountBalances(ctx, token, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		responseIDs = append(responseIDs, balances.RequestID)
+	}
+
+	if responseIDs[0] == responseIDs[1] {
+		t.Errorf("both requests have the request id %s", responseIDs[0])
+	}
+	for i, responseID := range responseIDs {
+		sent, ok := strings.CutPrefix(responseID, "nightly-")
+		if !ok {
+			t.Errorf("request id %q is not prefixed with the correlation id", responseID)
+		}
+		if transport.contextIDs[i] != responseID {
+			t.Errorf("context carries request id %q, the response %q", transport.contextIDs[i], responseID)
+		}
+		if !requestIDPattern.MatchString(transport.sentIDs[i]) || transport.sentIDs[i] != sent {
+			t.Errorf("sent request id %q, want the nine digits of %q", transport.sentIDs[i], responseID)
+		}
+	}
+}
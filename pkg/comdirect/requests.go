@@ -1,48 +1,125 @@
 package comdirect
 
 import (
-	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
-	"log/slog"
 	"net/http"
 	"strings"
 	"time"
 )
 
-const globalPageSize = 20
+// maxResponseSize limits the JSON responses read into memory, the largest pages are a few megabytes.
+var maxResponseSize int64 = 32 << 20
 
-func (c *Client) authenticatedRequest(req *http.Request, token *AuthToken, expectedStatus int) (io.Reader, *http.Header, error) {
-	c.ensureValidToken(token)
+// authenticatedRequest sends the request and unmarshals the JSON response into target.
+// If target is nil, the response body is discarded.
+// If target embeds ResponseMeta, the request id of the request is set on it.
+func (c *Client) authenticatedRequest(req *http.Request, token *AuthToken, expectedStatus int, target interface{}) (*http.Header, error) {
+	req, _ = withRequestID(req)
+	res, err := c.authenticatedResponse(req, token, expectedStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if target != nil {
+		// reading the whole body and unmarshaling it is faster than decoding while streaming, see BenchmarkAuthenticatedRequest
+		body, err := io.ReadAll(io.LimitReader(res.Body, maxResponseSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(body)) > maxResponseSize {
+			return nil, fmt.Errorf("response of %s %s exceeds %d bytes", req.Method, req.URL.Path, maxResponseSize)
+		}
+		if err := json.Unmarshal(body, target); err != nil {
+			return nil, err
+		}
+		if meta, ok := target.(responseMeta); ok {
+			meta.setRequestID(requestIDOf(req.Context()))
+		}
+	}
+
+	return &res.Header, nil
+}
+
+// authenticatedResponse sends the request and returns the response with an unread body.
+// The caller is responsible for closing the body.
+// Each request is sent with its own request id, see RequestIDFromContext.
+func (c *Client) authenticatedResponse(req *http.Request, token *AuthToken, expectedStatus int) (*http.Response, error) {
+	req, requestID := withRequestID(req)
+	if err := c.ensureValidToken(req.Context(), token); err != nil {
+		return nil, err
+	}
+	addXHTTPRequestInfoHeader(req, token.SessionGUID, requestID)
 	addAuthorizationHeader(req, token)
 	c.newAuthenticatedRequest()
 	token.Lock()
-	res, err := c.client.Do(req)
+	res, err := c.do(req)
 	token.Unlock()
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	defer res.Body.Close()
-
 	if res.StatusCode != expectedStatus {
-		return nil, nil, handleRequestError(res)
+		defer res.Body.Close()
+		return nil, c.handleRequestError(req, res)
 	}
 
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, nil, err
+	return res, nil
+}
+
+// do sends the request, setting the User-Agent and retrying according to the retry policy.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
 	}
 
-	reader := bytes.NewReader(body)
-	return reader, &res.Header, nil
+	backoff := c.retryPolicy.Backoff
+	for attempt := 0; ; attempt++ {
+		c.logger.DebugContext(req.Context(), "Sending request", "method", req.Method, "path", req.URL.Path, "requestId", requestIDOf(req.Context()), "attempt", attempt+1)
+		res, err := c.client.Do(req)
+		if err == nil {
+			c.logger.DebugContext(req.Context(), "Received response", "method", req.Method, "path", req.URL.Path, "requestId", requestIDOf(req.Context()), "status", res.StatusCode)
+		}
+		if attempt >= c.retryPolicy.MaxRetries || (req.Body != nil && req.GetBody == nil) {
+			return res, err
+		}
+		if err == nil && !c.retryPolicy.shouldRetry(res.StatusCode) {
+			return res, nil
+		}
+
+		if err != nil {
+			c.logger.Debug(fmt.Sprintf("Request failed, retrying in %s: %s", backoff, err))
+		} else {
+			c.logger.Debug(fmt.Sprintf("Request failed with status code %d, retrying in %s", res.StatusCode, backoff))
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
 }
 
 func (c *Client) newAuthenticatedRequest() {
-	currentTimeToSecond := time.Now().Truncate(time.Second)
+	currentTimeToSecond := c.clock.Now().Truncate(time.Second)
 	c.requestMonitor[currentTimeToSecond]++
 	if c.requestMonitor[currentTimeToSecond] > c.requestLimitPerSecond {
-		slog.Warn(fmt.Sprintf("Reaching request limit of %d for current second: %s", c.requestLimitPerSecond, currentTimeToSecond))
+		c.logger.Warn(fmt.Sprintf("Reaching request limit of %d for current second: %s", c.requestLimitPerSecond, currentTimeToSecond))
 	}
 }
 
@@ -50,27 +127,43 @@ type options interface {
 	queryParams() []string
 }
 
+// pagingQueryParams returns the paging parameters of list endpoints, zero values are left to the API defaults.
+func pagingQueryParams(first int, count int) []string {
+	queryParams := []string{}
+	if first > 0 {
+		queryParams = append(queryParams, fmt.Sprintf("paging-first=%d", first))
+	}
+	if count > 0 {
+		queryParams = append(queryParams, fmt.Sprintf("paging-count=%d", count))
+	}
+	return queryParams
+}
+
 func addQueryParams(url string, options options) string {
 	queryParams := []string{}
 	if options != nil {
 		queryParams = append(queryParams, options.queryParams()...)
 	}
+	if len(queryParams) == 0 {
+		return url
+	}
 	return fmt.Sprintf("%s?%s", url, strings.Join(queryParams, "&"))
 }
 
-func handleRequestError(res *http.Response) error {
-	statusErr := fmt.Errorf("request failed with status code %d", res.StatusCode)
+func (c *Client) handleRequestError(req *http.Request, res *http.Response) error {
+	requestID, _ := RequestIDFromContext(req.Context())
+	requestErr := &RequestError{
+		StatusCode: res.StatusCode,
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		RequestID:  requestID,
+	}
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
-		return statusErr
+		return requestErr
 	}
 	if len(body) != 0 {
-		slog.Debug(string(body))
+		c.logger.DebugContext(req.Context(), "Request failed", "status", res.StatusCode, "requestId", requestID, "body", c.logBody(body, res.Header.Get("Content-Type")))
 	}
-	return statusErr
-}
-
-func requestID() string {
-	time := time.Now()
-	return fmt.Sprintf("%d", time.UnixMilli())[10:]
+	return requestErr
 }
@@ -0,0 +1,149 @@
+package comdirect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// staticTransport answers every request with the same body.
+type staticTransport struct {
+	body []byte
+}
+
+func (t staticTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(t.body)),
+		Request:    req,
+	}, nil
+}
+
+// transactionsBody returns a page of n account transactions.
+func transactionsBody(n int) []byte {
+	var b strings.Builder
+	b.WriteString(`{"paging":{"index":0,"matches":` + fmt.Sprint(n) + `},"values":[`)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, `{"reference":"REF%06d","bookingStatus":"BOOKED","bookingDate":"2024-01-31","amount":{"value":"-12.34","unit":"EUR"},"remitter":{"holderName":"Erika Mustermann","iban":"DE02120300000000202051","bic":"BYLADEM1001"},"remittanceInfo":"01Invoice 4711 from 2024-01-01","transactionType":{"key":"DIRECT_DEBIT","text":"Lastschrift"}}`, i)
+	}
+	b.WriteString(`]}`)
+	return []byte(b.String())
+}
+
+func newBenchmarkClient(body []byte) (*Client, *AuthToken) {
+	client := NewClient(Config{APIURL: "https://api.example.com/api"},
+		WithTransport(staticTransport{body: body}),
+		WithRateLimit(math.MaxInt),
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	)
+	token := &AuthToken{AccessToken: "access", ExpiresIn: 599, CreationTime: time.Now(), SessionGUID: "session"}
+	return client, token
+}
+
+// streamingRequest decodes the body while reading it, like authenticatedRequest did before it read the whole body.
+func (c *Client) streamingRequest(req *http.Request, token *AuthToken, target interface{}) error {
+	res, err := c.authenticatedResponse(req, token, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return json.NewDecoder(res.Body).Decode(target)
+}
+
+// readAllDecodeRequest reads the whole body and decodes it from a bytes.Reader, like the client did originally.
+func (c *Client) readAllDecodeRequest(req *http.Request, token *AuthToken, target interface{}) error {
+	res, err := c.authenticatedResponse(req, token, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	return json.NewDecoder(bytes.NewReader(body)).Decode(target)
+}
+
+func benchmarkRequest(b *testing.B, transactions int, request func(c *Client, req *http.Request, token *AuthToken, target *AccountTransactions) error) {
+	client, token := newBenchmarkClient(transactionsBody(transactions))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://api.example.com/api/banking/v1/accounts/a/transactions", nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		var target AccountTransactions
+		if err := request(client, req, token, &target); err != nil {
+			b.Fatal(err)
+		}
+		if len(target.Values) != transactions {
+			b.Fatalf("decoded %d transactions", len(target.Values))
+		}
+	}
+}
+
+func unmarshalRequest(c *Client, req *http.Request, token *AuthToken, target *AccountTransactions) error {
+	_, err := c.authenticatedRequest(req, token, http.StatusOK, target)
+	return err
+}
+
+func streamingRequest(c *Client, req *http.Request, token *AuthToken, target *AccountTransactions) error {
+	return c.streamingRequest(req, token, target)
+}
+
+func readAllDecodeRequest(c *Client, req *http.Request, token *AuthToken, target *AccountTransactions) error {
+	return c.readAllDecodeRequest(req, token, target)
+}
+
+func BenchmarkAuthenticatedRequest(b *testing.B) {
+	for _, n := range []int{20, 500} {
+		b.Run(fmt.Sprintf("transactions=%d", n), func(b *testing.B) {
+			benchmarkRequest(b, n, unmarshalRequest)
+		})
+	}
+}
+
+func BenchmarkAuthenticatedRequestStreaming(b *testing.B) {
+	for _, n := range []int{20, 500} {
+		b.Run(fmt.Sprintf("transactions=%d", n), func(b *testing.B) {
+			benchmarkRequest(b, n, streamingRequest)
+		})
+	}
+}
+
+func BenchmarkAuthenticatedRequestReadAllDecode(b *testing.B) {
+	for _, n := range []int{20, 500} {
+		b.Run(fmt.Sprintf("transactions=%d", n), func(b *testing.B) {
+			benchmarkRequest(b, n, readAllDecodeRequest)
+		})
+	}
+}
+
+func TestAuthenticatedRequestRejectsOversizedResponse(t *testing.T) {
+	limit := maxResponseSize
+	maxResponseSize = 1024
+	defer func() { maxResponseSize = limit }()
+
+	client, token := newBenchmarkClient(transactionsBody(20))
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://api.example.com/api/banking/v1/accounts/a/transactions", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var target AccountTransactions
+	if _, err := client.authenticatedRequest(req, token, http.StatusOK, &target); err == nil || !strings.Contains(err.Error(), "exceeds 1024 bytes") {
+		t.Errorf("expected the oversized response to be rejected, got %v", err)
+	}
+}
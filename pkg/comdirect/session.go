@@ -0,0 +1,249 @@
+package comdirect
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"sync"
+	"time"
+)
+
+// sessionRefreshThreshold is the remaining lifetime below which a session refreshes its token.
+const sessionRefreshThreshold = 30 * time.Second
+
+// Session binds a client to a token.
+// The token is refreshed transparently before it expires, so callers don't have to handle refresh results.
+type Session struct {
+	client *Client
+	mu     sync.Mutex
+	token  *AuthToken
+}
+
+// AuthenticateSession authenticates the user and returns a session for the new token.
+// See Authenticate for details on the twoFaHandler.
+func (c *Client) AuthenticateSession(ctx context.Context, twoFaHandler func(tanHeader TANHeader) error) (*Session, error) {
+	token, err := c.authenticate(ctx, twoFaHandler)
+	if err != nil {
+		return nil, err
+	}
+	return c.NewSession(token), nil
+}
+
+// NewSession returns a session for an existing token, e.g. a token loaded from a cache.
+func (c *Client) NewSession(token *AuthToken) *Session {
+	return &Session{client: c, token: token}
+}
+
+// RestoreSession returns a session for the token persisted in the token store, see WithTokenStore.
+// ErrTokenNotFound is returned if no token is stored.
+// The token may be expired, it is refreshed with the first request.
+func (c *Client) RestoreSession() (*Session, error) {
+	if c.store == nil {
+		return nil, fmt.Errorf("no token store configured")
+	}
+	token, err := c.store.Load(c.storeKey)
+	if err != nil {
+		return nil, err
+	}
+	c.trackToken(token)
+	return c.NewSession(token), nil
+}
+
+// Token returns the token of the session, it is updated in place when the session refreshes it.
+func (s *Session) Token() *AuthToken {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.token
+}
+
+// SessionGUID returns the identifier of the session.
+func (s *Session) SessionGUID() string {
+	return s.Token().SessionGUID
+}
+
+// KDNR returns the customer number of the authenticated user.
+func (s *Session) KDNR() string {
+	return s.Token().KDNR
+}
+
+// BPID returns the business partner id of the authenticated user.
+func (s *Session) BPID() int {
+	return s.Token().BPID
+}
+
+// Scope returns the scope granted to the token.
+func (s *Session) Scope() string {
+	return s.Token().Scope
+}
+
+// Refresh refreshes the token of the session.
+func (s *Session) Refresh(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.refresh(ctx)
+}
+
+func (s *Session) refresh(ctx context.Context) error {
+	refreshed, err := s.client.refreshToken(ctx, s.token)
+	if err != nil {
+		return err
+	}
+	// the token is updated in place, so tokens returned by Token earlier stay valid
+	s.client.replaceToken(s.token, refreshed)
+	return nil
+}
+
+// Revoke revokes the token of the session, the session can't be used afterwards.
+func (s *Session) Revoke(ctx context.Context) error {
+	token, err := s.validToken(ctx)
+	if err != nil {
+		return err
+	}
+	return s.client.revokeToken(ctx, token)
+}
+
+// validToken returns the token of the session, refreshing it if it is about to expire.
+func (s *Session) validToken(ctx context.Context) (*AuthToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token.willExpireAt(s.client.clock.Now(), sessionRefreshThreshold) {
+		s.client.logger.Debug("Session token is about to expire, refreshing")
+		if err := s.refresh(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return s.token, nil
+}
+
+// AccountBalances returns the balances of all accounts of the user.
+func (s *Session) AccountBalances(ctx context.Context, options *AccountBalancesOptions) (*AccountBalances, error) {
+	token, err := s.validToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.client.accountBalances(ctx, token, options)
+}
+
+// AccountBalance returns the balance of a specific account.
+func (s *Session) AccountBalance(ctx context.Context, accountID string) (*AccountBalance, error) {
+	token, err := s.validToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.client.accountBalance(ctx, token, accountID)
+}
+
+// AccountTransactions returns the transactions of a specific account.
+func (s *Session) AccountTransactions(ctx context.Context, accountID string, options *AccountTransactionOptions) (*AccountTransactions, error) {
+	token, err := s.validToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.client.accountTransactions(ctx, token, accountID, options)
+}
+
+// PaginatedAccountTransactions returns up to amount transactions of a specific account.
+func (s *Session) PaginatedAccountTransactions(ctx context.Context, accountID string, amount int, options *AccountTransactionOptions) (*AccountTransactions, error) {
+	return s.client.paginatedAccountTransactions(ctx, s.validToken, accountID, amount, options)
+}
+
+// Depots returns the depots of the user.
+func (s *Session) Depots(ctx context.Context, options *DepotsOptions) (*Depots, error) {
+	token, err := s.validToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.client.depots(ctx, token, options)
+}
+
+// PaginatedDepots returns up to amount depots of the user.
+func (s *Session) PaginatedDepots(ctx context.Context, amount int) (*Depots, error) {
+	return s.client.paginatedDepots(ctx, s.validToken, amount)
+}
+
+// DepotPositions returns the positions of a depot.
+func (s *Session) DepotPositions(ctx context.Context, depotID string, options *DepotPosistionsOptions) (*DepotPositions, error) {
+	token, err := s.validToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.client.depotPositions(ctx, token, depotID, options)
+}
+
+// PaginatedDepotPositions returns up to amount positions of a depot.
+func (s *Session) PaginatedDepotPositions(ctx context.Context, depotID string, amount int, options *DepotPosistionsOptions) (*DepotPositions, error) {
+	return s.client.paginatedDepotPositions(ctx, s.validToken, depotID, amount, options)
+}
+
+// DepotPosition returns the position of a depot.
+func (s *Session) DepotPosition(ctx context.Context, depotID string, positionID string, options *DepotPositionOptions) (*DepotPosition, error) {
+	token, err := s.validToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.client.depotPosition(ctx, token, depotID, positionID, options)
+}
+
+// DepotTransactions returns the transactions of a depot.
+func (s *Session) DepotTransactions(ctx context.Context, depotID string, options *DepotTransactionOptions) (*DepotTransactions, error) {
+	token, err := s.validToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.client.depotTransactions(ctx, token, depotID, options)
+}
+
+// PaginatedDepotTransactions returns up to amount transactions of a depot.
+func (s *Session) PaginatedDepotTransactions(ctx context.Context, depotID string, amount int, options *DepotTransactionOptions) (*DepotTransactions, error) {
+	return s.client.paginatedDepotTransactions(ctx, s.validToken, depotID, amount, options)
+}
+
+// Documents returns the documents in the postbox of the user.
+func (s *Session) Documents(ctx context.Context, options *DocumentsOptions) (*Documents, error) {
+	token, err := s.validToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.client.documents(ctx, token, options)
+}
+
+// DownloadDocument streams the content of a document, the caller is responsible for closing the returned reader.
+func (s *Session) DownloadDocument(ctx context.Context, documentID string, mimeType string) (io.ReadCloser, error) {
+	token, err := s.validToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.client.downloadDocument(ctx, token, documentID, mimeType)
+}
+
+// AccountBalancePages returns an iterator over the pages of balances of all accounts of the user.
+func (s *Session) AccountBalancePages(ctx context.Context, options *AccountBalancesOptions) iter.Seq2[*AccountBalances, error] {
+	return s.client.accountBalancePages(ctx, s.validToken, options)
+}
+
+// AccountTransactionPages returns an iterator over the pages of transactions of an account.
+// The token is refreshed between pages, so long exports outlive the token lifetime.
+func (s *Session) AccountTransactionPages(ctx context.Context, accountID string, options *AccountTransactionOptions) iter.Seq2[*AccountTransactions, error] {
+	return s.client.accountTransactionPages(ctx, s.validToken, accountID, options)
+}
+
+// DepotPages returns an iterator over the pages of depots of the user.
+func (s *Session) DepotPages(ctx context.Context, options *DepotsOptions) iter.Seq2[*Depots, error] {
+	return s.client.depotPages(ctx, s.validToken, options)
+}
+
+// DepotPositionPages returns an iterator over the pages of positions of a depot.
+func (s *Session) DepotPositionPages(ctx context.Context, depotID string, options *DepotPosistionsOptions) iter.Seq2[*DepotPositions, error] {
+	return s.client.depotPositionPages(ctx, s.validToken, depotID, options)
+}
+
+// DepotTransactionPages returns an iterator over the pages of transactions of a depot.
+func (s *Session) DepotTransactionPages(ctx context.Context, depotID string, options *DepotTransactionOptions) iter.Seq2[*DepotTransactions, error] {
+	return s.client.depotTransactionPages(ctx, s.validToken, depotID, options)
+}
+
+// DocumentPages returns an iterator over the pages of documents in the postbox of the user.
+func (s *Session) DocumentPages(ctx context.Context, options *DocumentsOptions) iter.Seq2[*Documents, error] {
+	return s.client.documentPages(ctx, s.validToken, options)
+}
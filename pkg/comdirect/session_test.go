@@ -0,0 +1,36 @@
+package comdirect
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSessionRefreshUpdatesTokenInPlace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access_token":"new-access","refresh_token":"new-refresh","expires_in":599}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{TokenURL: server.URL + "/oauth/token"})
+	token := &AuthToken{AccessToken: "old-access", RefreshToken: "old-refresh", ExpiresIn: 599, CreationTime: time.Now(), SessionGUID: "session"}
+	session := client.NewSession(token)
+	earlier := session.Token()
+
+	if err := session.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if session.Token() != earlier {
+		t.Error("session replaced its token instead of updating it")
+	}
+	if earlier.AccessToken != "new-access" || earlier.RefreshToken != "new-refresh" {
+		t.Errorf("earlier token is stale: %q %q", earlier.AccessToken, earlier.RefreshToken)
+	}
+	if tracked := client.trackedTokens(); len(tracked) != 1 || !slices.Contains(tracked, token) {
+		t.Errorf("expected only the session token to be tracked, got %d tokens", len(tracked))
+	}
+}
@@ -0,0 +1,255 @@
+package comdirect
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"github.com/fbufler/comdirect/internal/secretbox"
+)
+
+var (
+	// ErrTokenNotFound is returned by a TokenStore if no token is stored for a key.
+	ErrTokenNotFound = errors.New("token not found")
+	// ErrTokenTampered is returned by the EncryptedFileTokenStore if a token can't be decrypted.
+	ErrTokenTampered = errors.New("stored token has been tampered with or the passphrase is wrong")
+	// ErrUnknownTokenFormat is returned by the EncryptedFileTokenStore if a file is not in the encrypted format.
+	ErrUnknownTokenFormat = errors.New("stored token has an unknown format")
+)
+
+// TokenStore persists tokens by key, e.g. a user or profile name.
+// Set it with WithTokenStore to persist tokens of a client automatically.
+type TokenStore interface {
+	Load(key string) (*AuthToken, error)
+	Save(key string, token *AuthToken) error
+	Delete(key string) error
+}
+
+var (
+	_ TokenStore = (*MemoryTokenStore)(nil)
+	_ TokenStore = (*FileTokenStore)(nil)
+	_ TokenStore = (*EncryptedFileTokenStore)(nil)
+)
+
+// MemoryTokenStore keeps tokens in memory, e.g. for tests or short living processes.
+// Tokens are stored as copies, so later changes to a saved token don't affect the store.
+type MemoryTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string][]byte
+}
+
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{tokens: make(map[string][]byte)}
+}
+
+func (s *MemoryTokenStore) Load(key string) (*AuthToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.tokens[key]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+	token := &AuthToken{}
+	if err := json.Unmarshal(data, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (s *MemoryTokenStore) Save(key string, token *AuthToken) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[key] = data
+	return nil
+}
+
+func (s *MemoryTokenStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, key)
+	return nil
+}
+
+// FileTokenStore stores each token as plain JSON file in a directory.
+// The files are only readable by the current user, use EncryptedFileTokenStore if that is not sufficient.
+type FileTokenStore struct {
+	dir string
+}
+
+func NewFileTokenStore(dir string) *FileTokenStore {
+	return &FileTokenStore{dir: dir}
+}
+
+// Path returns the path of the file the token for key is stored in.
+func (s *FileTokenStore) Path(key string) string {
+	return filepath.Join(s.dir, tokenFileName(key))
+}
+
+func (s *FileTokenStore) Load(key string) (*AuthToken, error) {
+	data, err := readTokenFile(s.Path(key))
+	if err != nil {
+		return nil, err
+	}
+	token := &AuthToken{}
+	if err := json.Unmarshal(data, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (s *FileTokenStore) Save(key string, token *AuthToken) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return writeTokenFile(s.Path(key), data)
+}
+
+func (s *FileTokenStore) Delete(key string) error {
+	return deleteTokenFile(s.Path(key))
+}
+
+// tokenMagic prefixes every file written by the EncryptedFileTokenStore.
+var tokenMagic = []byte("CDTC")
+
+// EncryptedFileTokenStore stores each token encrypted with AES-GCM in a directory.
+// The key is derived from the passphrase with scrypt, see secretbox for the file layout.
+type EncryptedFileTokenStore struct {
+	dir        string
+	passphrase string
+}
+
+func NewEncryptedFileTokenStore(dir string, passphrase string) *EncryptedFileTokenStore {
+	return &EncryptedFileTokenStore{dir: dir, passphrase: passphrase}
+}
+
+// Path returns the path of the file the token for key is stored in.
+func (s *EncryptedFileTokenStore) Path(key string) string {
+	return filepath.Join(s.dir, tokenFileName(key))
+}
+
+func (s *EncryptedFileTokenStore) Load(key string) (*AuthToken, error) {
+	data, err := readTokenFile(s.Path(key))
+	if err != nil {
+		return nil, err
+	}
+	return s.decrypt(data)
+}
+
+func (s *EncryptedFileTokenStore) Save(key string, token *AuthToken) error {
+	data, err := s.encrypt(token)
+	if err != nil {
+		return err
+	}
+	return writeTokenFile(s.Path(key), data)
+}
+
+func (s *EncryptedFileTokenStore) Delete(key string) error {
+	return deleteTokenFile(s.Path(key))
+}
+
+func (s *EncryptedFileTokenStore) encrypt(token *AuthToken) ([]byte, error) {
+	serializedToken, err := json.Marshal(token)
+	if err != nil {
+		return nil, err
+	}
+	return secretbox.Seal(s.passphrase, tokenMagic, serializedToken)
+}
+
+func (s *EncryptedFileTokenStore) decrypt(data []byte) (*AuthToken, error) {
+	serializedToken, err := secretbox.Open(s.passphrase, tokenMagic, data)
+	if errors.Is(err, secretbox.ErrUnknownFormat) {
+		return nil, ErrUnknownTokenFormat
+	}
+	if errors.Is(err, secretbox.ErrTampered) {
+		return nil, ErrTokenTampered
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	token := &AuthToken{}
+	if err := json.Unmarshal(serializedToken, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+var (
+	unsafeKeyCharacters = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+	digitsOnly          = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// tokenFileName returns a file name for the key which is safe on all platforms.
+// Keys containing other characters or only digits, e.g. a Zugangsnummer, are hashed so they don't show up in the file system.
+func tokenFileName(key string) string {
+	if key != "" && !unsafeKeyCharacters.MatchString(key) && !digitsOnly.MatchString(key) {
+		return key + ".token"
+	}
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:8]) + ".token"
+}
+
+func readTokenFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrTokenNotFound
+	}
+	return data, err
+}
+
+// writeTokenFile writes the data to a temporary file with strict permissions and renames it,
+// so readers never see a partially written token.
+func writeTokenFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), ".token-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+func deleteTokenFile(path string) error {
+	err := os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// persistToken saves the token in the token store, if one is configured.
+func (c *Client) persistToken(token *AuthToken) {
+	if c.store == nil {
+		return
+	}
+	if err := c.store.Save(c.storeKey, token); err != nil {
+		c.logger.Warn(fmt.Sprintf("Unable to persist token: %s", err))
+	}
+}
@@ -0,0 +1,26 @@
+package comdirect
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTokenFileName(t *testing.T) {
+	for key, plain := range map[string]bool{
+		"default":        true,
+		"company_2":      true,
+		"12345678":       false,
+		"user@example":   false,
+		"../etc/passwd":  false,
+		"":               false,
+		"household-2024": true,
+	} {
+		name := tokenFileName(key)
+		if got := name == key+".token"; got != plain {
+			t.Errorf("tokenFileName(%q) = %q, plain name expected: %t", key, name, plain)
+		}
+		if !plain && key != "" && strings.Contains(name, key) {
+			t.Errorf("tokenFileName(%q) = %q contains the key", key, name)
+		}
+	}
+}
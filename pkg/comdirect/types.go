@@ -2,23 +2,85 @@ package comdirect
 
 import "time"
 
+// AuthToken holds the tokens of an authenticated session.
+// RefreshExpiresIn is only set if the token response contains the lifetime of the refresh token, see RefreshExpiresAt.
+// TANType is the type of the TAN the session was activated with, e.g. P_TAN_PUSH.
 type AuthToken struct {
-	AccessToken  string
-	ExpiresIn    int
-	RefreshToken string
-	CreationTime time.Time
-	Scope        string
-	SessionGUID  string
-	RequestID    string
-	locked       bool
+	AccessToken      string
+	ExpiresIn        int
+	RefreshToken     string
+	RefreshExpiresIn int
+	CreationTime     time.Time
+	Scope            string
+	SessionGUID      string
+	// Deprecated: RequestID is no longer set, every request is sent with its own request id, see RequestIDFromContext.
+	RequestID string
+	TANType   string
+	KDNR      string
+	BPID      int
+	KontaktID int
+	locked    bool
 }
 
 func (t *AuthToken) IsExpired() bool {
-	return time.Since(t.CreationTime).Seconds() > float64(t.ExpiresIn)
+	return t.willExpireAt(time.Now(), 0)
 }
 
 func (t *AuthToken) WillExpireIn(seconds time.Duration) bool {
-	return time.Since(t.CreationTime).Seconds()+seconds.Seconds() > float64(t.ExpiresIn)
+	return t.willExpireAt(time.Now(), seconds)
+}
+
+// ExpiresAt returns the time the access token expires.
+func (t *AuthToken) ExpiresAt() time.Time {
+	return t.CreationTime.Add(time.Duration(t.ExpiresIn) * time.Second)
+}
+
+// refreshTokenLifetime is the documented lifetime of a refresh token, every refresh starts it again.
+const refreshTokenLifetime = 20 * time.Minute
+
+// RefreshExpiresAt returns the time the refresh token expires.
+// The lifetime sent by the token endpoint is used if present, the documented 20 minutes otherwise.
+func (t *AuthToken) RefreshExpiresAt() time.Time {
+	if t.RefreshExpiresIn == 0 {
+		return t.CreationTime.Add(refreshTokenLifetime)
+	}
+	return t.CreationTime.Add(time.Duration(t.RefreshExpiresIn) * time.Second)
+}
+
+func (t *AuthToken) willExpireAt(now time.Time, threshold time.Duration) bool {
+	return now.Sub(t.CreationTime).Seconds()+threshold.Seconds() > float64(t.ExpiresIn)
+}
+
+// inheritSessionInfo copies the customer and session information from previous, if it is missing in the token.
+// The information is not part of every token response.
+func (t *AuthToken) inheritSessionInfo(previous *AuthToken) {
+	if t.TANType == "" {
+		t.TANType = previous.TANType
+	}
+	if t.KDNR == "" {
+		t.KDNR = previous.KDNR
+	}
+	if t.BPID == 0 {
+		t.BPID = previous.BPID
+	}
+	if t.KontaktID == 0 {
+		t.KontaktID = previous.KontaktID
+	}
+}
+
+// update copies the tokens and session information of refreshed into t, the lock state of t is kept.
+func (t *AuthToken) update(refreshed *AuthToken) {
+	t.AccessToken = refreshed.AccessToken
+	t.ExpiresIn = refreshed.ExpiresIn
+	t.RefreshToken = refreshed.RefreshToken
+	t.RefreshExpiresIn = refreshed.RefreshExpiresIn
+	t.CreationTime = refreshed.CreationTime
+	t.Scope = refreshed.Scope
+	t.SessionGUID = refreshed.SessionGUID
+	t.TANType = refreshed.TANType
+	t.KDNR = refreshed.KDNR
+	t.BPID = refreshed.BPID
+	t.KontaktID = refreshed.KontaktID
 }
 
 func (t *AuthToken) Lock() {
@@ -38,10 +100,12 @@ type authResponse struct {
 	TokenType    string `json:"token_type"`
 	RefreshToken string `json:"refresh_token"`
 	ExpiresIn    int    `json:"expires_in"`
-	Scope        string `json:"scope"`
-	KDNR         string `json:"kdnr"`
-	BPID         int    `json:"bpid"`
-	KontaktId    int    `json:"kontaktId"`
+	// RefreshExpiresIn is not documented, it overrides the documented lifetime if the token endpoint sends it
+	RefreshExpiresIn int    `json:"refresh_token_expires_in"`
+	Scope            string `json:"scope"`
+	KDNR             string `json:"kdnr"`
+	BPID             int    `json:"bpid"`
+	KontaktId        int    `json:"kontaktId"`
 }
 
 type session struct {
@@ -67,11 +131,13 @@ type Balance struct {
 }
 
 type AccountBalances struct {
+	ResponseMeta
 	Paging Paging           `json:"paging"`
 	Values []AccountBalance `json:"values"`
 }
 
 type AccountBalance struct {
+	ResponseMeta
 	Account                Account `json:"account"`
 	AccountID              string  `json:"accountId"`
 	Balance                Balance `json:"balance"`
@@ -97,6 +163,7 @@ type AccountType struct {
 }
 
 type AccountTransactions struct {
+	ResponseMeta
 	Paging                 Paging                        `json:"paging"`
 	AggregatedTransactions AggregatedAccountTransactions `json:"aggregated"`
 	Values                 []AccountTransaction          `json:"values"`
@@ -116,8 +183,8 @@ type AccountTransaction struct {
 	BookingStatus         string                 `json:"bookingStatus"`
 	BookingDate           string                 `json:"bookingDate"`
 	Amount                Balance                `json:"amount"`
-	Remitter              Account                `json:"remitter"`
-	Deptor                Account                `json:"deptor"`
+	Remitter              AccountInformation     `json:"remitter"`
+	Deptor                AccountInformation     `json:"deptor"`
 	Creditor              Creditor               `json:"creditor"`
 	ValutaDate            string                 `json:"valutaDate"`
 	DirectDebitCreditorID string                 `json:"directDebitCreditorId"`
@@ -128,6 +195,13 @@ type AccountTransaction struct {
 	TransactionType       AccountTransactionType `json:"transactionType"`
 }
 
+// AccountInformation is the holder and account of the other party of a transaction.
+type AccountInformation struct {
+	HolderName string `json:"holderName"`
+	IBAN       string `json:"iban"`
+	BIC        string `json:"bic"`
+}
+
 type Creditor struct {
 	HolderName string `json:"holderName"`
 	IBAN       string `json:"iban"`
@@ -140,6 +214,7 @@ type AccountTransactionType struct {
 }
 
 type Depots struct {
+	ResponseMeta
 	Paging Paging  `json:"paging"`
 	Values []Depot `json:"values"`
 }
@@ -155,6 +230,7 @@ type Depot struct {
 }
 
 type DepotPositions struct {
+	ResponseMeta
 	Paging              Paging                   `json:"paging"`
 	AggregatedPositions AggregatedDepotPositions `json:"aggregated"`
 	Values              []DepotPosition          `json:"values"`
@@ -177,27 +253,29 @@ type AggregatedDepotPositions struct {
 }
 
 type DepotPosition struct {
-	DepotID                   string  `json:"depotId"`
-	PositionID                string  `json:"positionId"`
-	WKN                       string  `json:"wkn"`
-	CustodyType               string  `json:"custodyType"`
-	Quantity                  Balance `json:"quantity"`
-	AvailableQuantity         Balance `json:"availableQuantity"`
-	CurrentPrice              Price   `json:"currentPrice"`
-	PurchasePrice             Balance `json:"purchasePrice"`
-	PrevDayPrice              Price   `json:"prevDayPrice"`
-	CurrentValue              Balance `json:"currentValue"`
-	PurchaseValue             Balance `json:"purchaseValue"`
-	ProfitLossPurchaseAbs     Balance `json:"profitLossPurchaseAbs"`
-	ProfitLossPurchaseRel     string  `json:"profitLossPurchaseRel"`
-	ProfitLossPrevDayAbs      Balance `json:"profitLossPrevDayAbs"`
-	ProfitLossPrevDayRel      string  `json:"profitLossPrevDayRel"`
-	ProfitLossPrevDayTotalAbs Balance `json:"profitLossPrevDayTotalAbs"`
-	Version                   string  `json:"version"`
-	Hedgeability              string  `json:"hedgeability"`
-	AvailableQuantityToHedge  Balance `json:"availableQuantityToHedge"`
-	CurrentPriceDeterminable  bool    `json:"currentPriceDeterminable"`
-	HasIntraDayExecutedOrder  bool    `json:"hasIntraDayExecutedOrder"`
+	ResponseMeta
+	DepotID                   string      `json:"depotId"`
+	PositionID                string      `json:"positionId"`
+	WKN                       string      `json:"wkn"`
+	Instrument                *Instrument `json:"instrument,omitempty"`
+	CustodyType               string      `json:"custodyType"`
+	Quantity                  Balance     `json:"quantity"`
+	AvailableQuantity         Balance     `json:"availableQuantity"`
+	CurrentPrice              Price       `json:"currentPrice"`
+	PurchasePrice             Balance     `json:"purchasePrice"`
+	PrevDayPrice              Price       `json:"prevDayPrice"`
+	CurrentValue              Balance     `json:"currentValue"`
+	PurchaseValue             Balance     `json:"purchaseValue"`
+	ProfitLossPurchaseAbs     Balance     `json:"profitLossPurchaseAbs"`
+	ProfitLossPurchaseRel     string      `json:"profitLossPurchaseRel"`
+	ProfitLossPrevDayAbs      Balance     `json:"profitLossPrevDayAbs"`
+	ProfitLossPrevDayRel      string      `json:"profitLossPrevDayRel"`
+	ProfitLossPrevDayTotalAbs Balance     `json:"profitLossPrevDayTotalAbs"`
+	Version                   string      `json:"version"`
+	Hedgeability              string      `json:"hedgeability"`
+	AvailableQuantityToHedge  Balance     `json:"availableQuantityToHedge"`
+	CurrentPriceDeterminable  bool        `json:"currentPriceDeterminable"`
+	HasIntraDayExecutedOrder  bool        `json:"hasIntraDayExecutedOrder"`
 }
 
 type Price struct {
@@ -214,6 +292,7 @@ type Venue struct {
 }
 
 type DepotTransactions struct {
+	ResponseMeta
 	Paging Paging             `json:"paging"`
 	Values []DepotTransaction `json:"values"`
 }
@@ -252,3 +331,26 @@ type StaticInstrumentData struct {
 	FundRedemptionLimited  bool   `json:"fundRedemptionLimited"`
 	SavingsPlanEligibility string `json:"savingsPlanEligibility"`
 }
+
+type Documents struct {
+	ResponseMeta
+	Paging Paging     `json:"paging"`
+	Values []Document `json:"values"`
+}
+
+type Document struct {
+	DocumentID       string           `json:"documentId"`
+	Name             string           `json:"name"`
+	DateCreation     string           `json:"dateCreation"`
+	MimeType         string           `json:"mimeType"`
+	Deletable        bool             `json:"deletable"`
+	Advertisement    bool             `json:"advertisement"`
+	DocumentMetaData DocumentMetaData `json:"documentMetaData"`
+}
+
+type DocumentMetaData struct {
+	Archived          bool   `json:"archived"`
+	DateRead          string `json:"dateRead"`
+	AlreadyRead       bool   `json:"alreadyRead"`
+	PredocumentExists bool   `json:"predocumentExists"`
+}